@@ -0,0 +1,150 @@
+package glitch
+
+import (
+	"time"
+)
+
+// A single frame of an animation
+type AnimationFrame struct {
+	Sprite *Sprite
+	Duration time.Duration // Frames without a duration are shown for DefaultFrameDuration
+}
+
+// The duration used for frames that don't have one (Ex: TexturePacker sheets, which don't store frame timing). This matches Aseprite's default
+const DefaultFrameDuration = 100 * time.Millisecond
+
+// A named sequence of frames, such as an Aseprite tag
+type Animation struct {
+	Name string
+	Frames []AnimationFrame // Frames in the order they should be played
+	PingPong bool // If true, the animation plays forward then backward
+}
+
+// Creates an animated sprite that plays this animation on repeat
+func (a Animation) AnimatedSprite() *AnimatedSprite {
+	if a.PingPong {
+		return NewAnimatedSprite(a.Frames, AnimationPingPong)
+	}
+	return NewAnimatedSprite(a.Frames, AnimationLoop)
+}
+
+type AnimationMode uint8
+const (
+	AnimationLoop AnimationMode = iota // Restart from the first frame after the last frame
+	AnimationPingPong // Play forward to the last frame, then backward to the first frame, and repeat
+	AnimationOnce // Stop on the last frame
+)
+
+// A sprite which steps through a sequence of frames over time
+type AnimatedSprite struct {
+	frames []AnimationFrame
+	mode AnimationMode
+	index int
+	direction int // +1 when playing forward, -1 when playing backward (for ping pong)
+	elapsed time.Duration // Time spent on the current frame
+	done bool
+
+	OnDone func() // Called from Update when an AnimationOnce animation finishes its last frame
+}
+
+func NewAnimatedSprite(frames []AnimationFrame, mode AnimationMode) *AnimatedSprite {
+	return &AnimatedSprite{
+		frames: frames,
+		mode: mode,
+		index: 0,
+		direction: 1,
+	}
+}
+
+// Advances the animation by dt
+func (a *AnimatedSprite) Update(dt time.Duration) {
+	if a.done || len(a.frames) <= 0 { return }
+
+	a.elapsed += dt
+	for !a.done {
+		duration := a.frames[a.index].Duration
+		if duration <= 0 {
+			duration = DefaultFrameDuration
+		}
+		if a.elapsed < duration { break }
+
+		a.elapsed -= duration
+		a.step()
+	}
+}
+
+func (a *AnimatedSprite) step() {
+	last := len(a.frames) - 1
+	switch a.mode {
+	case AnimationLoop:
+		a.index = (a.index + 1) % len(a.frames)
+	case AnimationPingPong:
+		if last == 0 { return }
+		next := a.index + a.direction
+		if next < 0 || next > last {
+			a.direction = -a.direction
+			next = a.index + a.direction
+		}
+		a.index = next
+	case AnimationOnce:
+		if a.index >= last {
+			a.done = true
+			a.elapsed = 0
+			if a.OnDone != nil {
+				a.OnDone()
+			}
+			return
+		}
+		a.index++
+	}
+}
+
+// Restarts the animation from the first frame
+func (a *AnimatedSprite) Reset() {
+	a.index = 0
+	a.direction = 1
+	a.elapsed = 0
+	a.done = false
+}
+
+func (a *AnimatedSprite) SetMode(mode AnimationMode) {
+	a.mode = mode
+}
+
+// Returns true if the animation was played in AnimationOnce mode and has reached its last frame
+func (a *AnimatedSprite) Done() bool {
+	return a.done
+}
+
+// Returns the index of the current frame
+func (a *AnimatedSprite) Index() int {
+	return a.index
+}
+
+// Jumps to a specific frame
+func (a *AnimatedSprite) SetIndex(index int) {
+	if index < 0 || index >= len(a.frames) { return }
+	a.index = index
+	a.elapsed = 0
+}
+
+// Returns the sprite of the current frame
+func (a *AnimatedSprite) Sprite() *Sprite {
+	if len(a.frames) <= 0 { return nil }
+	return a.frames[a.index].Sprite
+}
+
+func (a *AnimatedSprite) Bounds() Rect {
+	sprite := a.Sprite()
+	if sprite == nil { return Rect{} }
+	return sprite.Bounds()
+}
+
+func (a *AnimatedSprite) Draw(target BatchTarget, matrix Mat4) {
+	a.DrawColorMask(target, matrix, RGBA{1.0, 1.0, 1.0, 1.0})
+}
+func (a *AnimatedSprite) DrawColorMask(target BatchTarget, matrix Mat4, mask RGBA) {
+	sprite := a.Sprite()
+	if sprite == nil { return }
+	sprite.DrawColorMask(target, matrix, mask)
+}
@@ -0,0 +1,100 @@
+package glitch
+
+import (
+	"testing"
+	"time"
+)
+
+func testFrames(durations ...time.Duration) []AnimationFrame {
+	frames := make([]AnimationFrame, len(durations))
+	for i := range durations {
+		frames[i] = AnimationFrame{Sprite: &Sprite{}, Duration: durations[i]}
+	}
+	return frames
+}
+
+func TestAnimatedSprite(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct{
+		name string
+		frames []AnimationFrame
+		mode AnimationMode
+		updates []time.Duration
+		indices []int // The frame index after each update
+	}{
+		{"loop", testFrames(100*ms, 100*ms, 100*ms), AnimationLoop,
+			[]time.Duration{50*ms, 50*ms, 100*ms, 100*ms, 100*ms},
+			[]int{0, 1, 2, 0, 1}},
+		{"loop skips frames on big steps", testFrames(100*ms, 100*ms, 100*ms), AnimationLoop,
+			[]time.Duration{250*ms, 100*ms},
+			[]int{2, 0}},
+		{"durations", testFrames(100*ms, 300*ms, 50*ms), AnimationLoop,
+			[]time.Duration{100*ms, 200*ms, 100*ms, 50*ms},
+			[]int{1, 1, 2, 0}},
+		{"ping pong", testFrames(100*ms, 100*ms, 100*ms), AnimationPingPong,
+			[]time.Duration{100*ms, 100*ms, 100*ms, 100*ms, 100*ms, 100*ms},
+			[]int{1, 2, 1, 0, 1, 2}},
+		{"ping pong single frame", testFrames(100*ms), AnimationPingPong,
+			[]time.Duration{100*ms, 100*ms},
+			[]int{0, 0}},
+		{"once", testFrames(100*ms, 100*ms), AnimationOnce,
+			[]time.Duration{100*ms, 100*ms, 500*ms},
+			[]int{1, 1, 1}},
+		{"frames without a duration", testFrames(100*ms, 0, -ms), AnimationLoop,
+			[]time.Duration{100*ms, DefaultFrameDuration, DefaultFrameDuration},
+			[]int{1, 2, 0}},
+		{"no durations", testFrames(0, 0), AnimationLoop,
+			[]time.Duration{DefaultFrameDuration/2, DefaultFrameDuration/2, DefaultFrameDuration * 3},
+			[]int{0, 1, 0}},
+	}
+
+	for _, test := range tests {
+		anim := NewAnimatedSprite(test.frames, test.mode)
+		for i, dt := range test.updates {
+			anim.Update(dt)
+			if anim.Index() != test.indices[i] {
+				t.Fatalf("%s: update %d: expected frame %d, got %d", test.name, i, test.indices[i], anim.Index())
+			}
+			if anim.Sprite() != test.frames[anim.Index()].Sprite {
+				t.Fatalf("%s: update %d: wrong sprite", test.name, i)
+			}
+		}
+	}
+}
+
+func TestAnimatedSpriteOnce(t *testing.T) {
+	ms := time.Millisecond
+	anim := NewAnimatedSprite(testFrames(100*ms, 100*ms), AnimationOnce)
+	calls := 0
+	anim.OnDone = func() { calls++ }
+
+	anim.Update(150*ms)
+	if anim.Done() || calls != 0 {
+		t.Fatalf("Finished early")
+	}
+	anim.Update(50*ms)
+	if !anim.Done() || calls != 1 || anim.Index() != 1 {
+		t.Fatalf("Expected to finish on the last frame: done %v, calls %d, index %d", anim.Done(), calls, anim.Index())
+	}
+	anim.Update(time.Second)
+	if calls != 1 {
+		t.Fatalf("OnDone should only be called once, got %d calls", calls)
+	}
+
+	anim.Reset()
+	if anim.Done() || anim.Index() != 0 {
+		t.Fatalf("Reset didn't restart the animation")
+	}
+	anim.Update(200*ms)
+	if calls != 2 {
+		t.Fatalf("Expected OnDone after replaying, got %d calls", calls)
+	}
+}
+
+func TestAnimatedSpriteEmpty(t *testing.T) {
+	anim := NewAnimatedSprite(nil, AnimationLoop)
+	anim.Update(time.Second)
+	if anim.Sprite() != nil || anim.Bounds() != (Rect{}) {
+		t.Fatalf("Empty animations shouldn't have a sprite")
+	}
+}
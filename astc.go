@@ -0,0 +1,611 @@
+package glitch
+
+import (
+	"math/bits"
+)
+
+// --------------------------------------------------------------------------------
+// - ASTC
+// --------------------------------------------------------------------------------
+// https://registry.khronos.org/DataFormat/specs/1.3/dataformat.1.3.html#ASTC
+// Only the LDR profile is decoded. Blocks which are invalid or need the HDR profile decode to the error color
+
+var astcErrorColor = [4]uint8{0xFF, 0x00, 0xFF, 0xFF}
+
+// A quantization range of the integer sequence encoding. Each value is stored as some low bits and an optional trit or quint
+type astcRange struct {
+	trits, quints, bits int
+}
+
+// The ranges in order: 2, 3, 4, 5, 6, 8, 10, 12, 16, 20, 24, 32, 40, 48, 64, 80, 96, 128, 160, 192, 256
+var astcRanges = [21]astcRange{
+	{0, 0, 1}, {1, 0, 0}, {0, 0, 2}, {0, 1, 0}, {1, 0, 1}, {0, 0, 3}, {0, 1, 1},
+	{1, 0, 2}, {0, 0, 4}, {0, 1, 2}, {1, 0, 3}, {0, 0, 5}, {0, 1, 3}, {1, 0, 4},
+	{0, 0, 6}, {0, 1, 4}, {1, 0, 5}, {0, 0, 7}, {0, 1, 5}, {1, 0, 6}, {0, 0, 8},
+}
+
+// The smallest range that color endpoints can use (0..5)
+const astcMinColorRange = 4
+
+// Returns the number of bits used to encode count values in a range
+func astcSequenceBits(count, rangeIndex int) int {
+	r := astcRanges[rangeIndex]
+	n := count * r.bits
+	if r.trits > 0 {
+		n += ((8 * count) + 4) / 5
+	}
+	if r.quints > 0 {
+		n += ((7 * count) + 2) / 3
+	}
+	return n
+}
+
+// Decodes count values of an integer sequence stored in the bits [start, end) of a block. Bits past the end read as 0
+func astcDecodeSequence(r blockReader, start, end, count, rangeIndex int) []int {
+	rng := astcRanges[rangeIndex]
+	pos := start
+	read := func(n int) int {
+		v := r.bitsAt(pos, n)
+		if pos + n > end {
+			if pos >= end {
+				v = 0
+			} else {
+				v &= (1 << (end - pos)) - 1
+			}
+		}
+		pos += n
+		return v
+	}
+
+	out := make([]int, 0, count + 4)
+	for len(out) < count {
+		switch {
+		case rng.trits > 0:
+			// Five values share 8 bits of trit data, which are interleaved with the low bits
+			var m [5]int
+			m[0] = read(rng.bits); t := read(2)
+			m[1] = read(rng.bits); t |= read(2) << 2
+			m[2] = read(rng.bits); t |= read(1) << 4
+			m[3] = read(rng.bits); t |= read(2) << 5
+			m[4] = read(rng.bits); t |= read(1) << 7
+			trits := astcTrits(t)
+			for i := range m {
+				out = append(out, (trits[i] << rng.bits) | m[i])
+			}
+		case rng.quints > 0:
+			// Three values share 7 bits of quint data
+			var m [3]int
+			m[0] = read(rng.bits); q := read(3)
+			m[1] = read(rng.bits); q |= read(2) << 3
+			m[2] = read(rng.bits); q |= read(2) << 5
+			quints := astcQuints(q)
+			for i := range m {
+				out = append(out, (quints[i] << rng.bits) | m[i])
+			}
+		default:
+			out = append(out, read(rng.bits))
+		}
+	}
+	return out[:count]
+}
+
+// Unpacks the five trits stored in 8 bits
+func astcTrits(T int) [5]int {
+	var t [5]int
+	var C int
+	if (T >> 2) & 7 == 7 {
+		C = (((T >> 5) & 7) << 2) | (T & 3)
+		t[4], t[3] = 2, 2
+	} else {
+		C = T & 0x1F
+		if (T >> 5) & 3 == 3 {
+			t[4], t[3] = 2, (T >> 7) & 1
+		} else {
+			t[4], t[3] = (T >> 7) & 1, (T >> 5) & 3
+		}
+	}
+
+	c := func(i int) int { return (C >> i) & 1 }
+	if C & 3 == 3 {
+		t[2], t[1], t[0] = 2, c(4), (c(3) << 1) | (c(2) &^ c(3))
+	} else if (C >> 2) & 3 == 3 {
+		t[2], t[1], t[0] = 2, 2, C & 3
+	} else {
+		t[2], t[1], t[0] = c(4), (C >> 2) & 3, (c(1) << 1) | (c(0) &^ c(1))
+	}
+	return t
+}
+
+// Unpacks the three quints stored in 7 bits
+func astcQuints(Q int) [3]int {
+	var q [3]int
+	b := func(i int) int { return (Q >> i) & 1 }
+	if (Q >> 1) & 3 == 3 && (Q >> 5) & 3 == 0 {
+		q[2] = (b(0) << 2) | ((b(4) &^ b(0)) << 1) | (b(3) &^ b(0))
+		q[1], q[0] = 4, 4
+		return q
+	}
+
+	var C int
+	if (Q >> 1) & 3 == 3 {
+		q[2] = 4
+		C = (((Q >> 3) & 3) << 3) | ((^Q >> 5) & 3) << 1 | b(0)
+	} else {
+		q[2] = (Q >> 5) & 3
+		C = Q & 0x1F
+	}
+	if C & 7 == 5 {
+		q[1], q[0] = 4, (C >> 3) & 3
+	} else {
+		q[1], q[0] = (C >> 3) & 3, C & 7
+	}
+	return q
+}
+
+// Expands an n bit value to m bits by replicating it
+func astcReplicate(v, n, m int) int {
+	if n == 0 { return 0 }
+	out := 0
+	for shift := m - n; shift > -n; shift -= n {
+		if shift >= 0 {
+			out |= v << shift
+		} else {
+			out |= v >> -shift
+		}
+	}
+	return out
+}
+
+// Unquantizes a color endpoint value to 0..255
+func astcUnquantizeColor(v, rangeIndex int) int {
+	rng := astcRanges[rangeIndex]
+	if rng.trits == 0 && rng.quints == 0 {
+		return astcReplicate(v, rng.bits, 8)
+	}
+
+	m := v & ((1 << rng.bits) - 1)
+	D := v >> rng.bits
+	A := 0
+	if m & 1 != 0 {
+		A = 0x1FF
+	}
+	hi := m >> 1 // The bits above the lowest, which are spread across B
+
+	var B, C int
+	switch {
+	case rng.trits > 0 && rng.bits == 1: B, C = 0, 204
+	case rng.quints > 0 && rng.bits == 1: B, C = 0, 113
+	case rng.trits > 0 && rng.bits == 2: B, C = hi * 0x116, 93
+	case rng.quints > 0 && rng.bits == 2: B, C = hi * 0x10C, 54
+	case rng.trits > 0 && rng.bits == 3: B, C = (hi << 7) | (hi << 2) | hi, 44
+	case rng.quints > 0 && rng.bits == 3: B, C = (hi << 7) | (hi << 1) | (hi >> 1), 26
+	case rng.trits > 0 && rng.bits == 4: B, C = (hi << 6) | hi, 22
+	case rng.quints > 0 && rng.bits == 4: B, C = (hi << 6) | (hi >> 1), 13
+	case rng.trits > 0 && rng.bits == 5: B, C = (hi << 5) | (hi >> 2), 11
+	case rng.quints > 0 && rng.bits == 5: B, C = (hi << 5) | (hi >> 3), 6
+	case rng.trits > 0 && rng.bits == 6: B, C = (hi << 4) | (hi >> 4), 5
+	}
+
+	T := ((D * C) + B) ^ A
+	return (A & 0x80) | (T >> 2)
+}
+
+// Unquantizes a weight to 0..64
+func astcUnquantizeWeight(v, rangeIndex int) int {
+	rng := astcRanges[rangeIndex]
+	if rng.bits == 0 {
+		if rng.trits > 0 {
+			return v * 32
+		}
+		return v * 16
+	}
+
+	var w int
+	if rng.trits == 0 && rng.quints == 0 {
+		w = astcReplicate(v, rng.bits, 6)
+	} else {
+		m := v & ((1 << rng.bits) - 1)
+		D := v >> rng.bits
+		A := 0
+		if m & 1 != 0 {
+			A = 0x7F
+		}
+		hi := m >> 1
+
+		var B, C int
+		switch {
+		case rng.trits > 0 && rng.bits == 1: B, C = 0, 50
+		case rng.quints > 0 && rng.bits == 1: B, C = 0, 28
+		case rng.trits > 0 && rng.bits == 2: B, C = hi * 0x45, 23
+		case rng.quints > 0 && rng.bits == 2: B, C = hi * 0x42, 13
+		case rng.trits > 0 && rng.bits == 3: B, C = (hi << 5) | hi, 11
+		}
+
+		T := ((D * C) + B) ^ A
+		w = (A & 0x20) | (T >> 2)
+	}
+
+	if w > 32 {
+		w++
+	}
+	return w
+}
+
+// Decodes the block mode (the low 11 bits of a block) into the weight grid size, whether there are two weight planes, and the weight range
+func astcBlockMode(mode int) (wx, wy int, dualPlane bool, rangeIndex int, ok bool) {
+	r := (mode >> 4) & 1
+	h := (mode >> 9) & 1
+	d := (mode >> 10) & 1
+	a := (mode >> 5) & 3
+
+	if mode & 3 != 0 {
+		r |= (mode & 3) << 1
+		b := (mode >> 7) & 3
+		switch (mode >> 2) & 3 {
+		case 0:
+			wx, wy = b + 4, a + 2
+		case 1:
+			wx, wy = b + 8, a + 2
+		case 2:
+			wx, wy = a + 2, b + 8
+		case 3:
+			b &= 1
+			if mode & 0x100 != 0 {
+				wx, wy = b + 2, a + 2
+			} else {
+				wx, wy = a + 2, b + 6
+			}
+		}
+	} else {
+		r |= ((mode >> 2) & 3) << 1
+		if (mode >> 2) & 3 == 0 {
+			return 0, 0, false, 0, false
+		}
+		b := (mode >> 9) & 3
+		switch (mode >> 7) & 3 {
+		case 0:
+			wx, wy = 12, a + 2
+		case 1:
+			wx, wy = a + 2, 12
+		case 2:
+			wx, wy = a + 6, b + 6
+			d, h = 0, 0
+		case 3:
+			switch a {
+			case 0:
+				wx, wy = 6, 10
+			case 1:
+				wx, wy = 10, 6
+			default:
+				return 0, 0, false, 0, false
+			}
+		}
+	}
+
+	return wx, wy, d == 1, (r - 2) + (6 * h), true
+}
+
+// Splits the low bit of a into b and returns a as a signed offset from b
+func astcBitTransferSigned(a, b int) (int, int) {
+	b = (b >> 1) | (a & 0x80)
+	a = (a >> 1) & 0x3F
+	if a & 0x20 != 0 {
+		a -= 0x40
+	}
+	return a, b
+}
+
+func astcBlueContract(r, g, b, a int) [4]int {
+	return [4]int{(r + b) >> 1, (g + b) >> 1, b, a}
+}
+
+// Decodes the two endpoints of a color endpoint mode. Returns false for the HDR modes
+func astcEndpoints(cem int, v []int) (e0, e1 [4]int, ok bool) {
+	switch cem {
+	case 0: // Luminance
+		e0 = [4]int{v[0], v[0], v[0], 255}
+		e1 = [4]int{v[1], v[1], v[1], 255}
+	case 1: // Luminance, base and offset
+		l0 := (v[0] >> 2) | (v[1] & 0xC0)
+		l1 := l0 + (v[1] & 0x3F)
+		if l1 > 255 {
+			l1 = 255
+		}
+		e0 = [4]int{l0, l0, l0, 255}
+		e1 = [4]int{l1, l1, l1, 255}
+	case 4: // Luminance and alpha
+		e0 = [4]int{v[0], v[0], v[0], v[2]}
+		e1 = [4]int{v[1], v[1], v[1], v[3]}
+	case 5: // Luminance and alpha, base and offset
+		dl, l := astcBitTransferSigned(v[1], v[0])
+		da, a := astcBitTransferSigned(v[3], v[2])
+		e0 = [4]int{l, l, l, a}
+		e1 = [4]int{l + dl, l + dl, l + dl, a + da}
+	case 6: // RGB, base and scale
+		e0 = [4]int{(v[0] * v[3]) >> 8, (v[1] * v[3]) >> 8, (v[2] * v[3]) >> 8, 255}
+		e1 = [4]int{v[0], v[1], v[2], 255}
+	case 8, 12: // RGB(A)
+		a0, a1 := 255, 255
+		if cem == 12 {
+			a0, a1 = v[6], v[7]
+		}
+		if v[1] + v[3] + v[5] >= v[0] + v[2] + v[4] {
+			e0 = [4]int{v[0], v[2], v[4], a0}
+			e1 = [4]int{v[1], v[3], v[5], a1}
+		} else {
+			e0 = astcBlueContract(v[1], v[3], v[5], a1)
+			e1 = astcBlueContract(v[0], v[2], v[4], a0)
+		}
+	case 9, 13: // RGB(A), base and offset
+		dr, r := astcBitTransferSigned(v[1], v[0])
+		dg, g := astcBitTransferSigned(v[3], v[2])
+		db, b := astcBitTransferSigned(v[5], v[4])
+		da, a := 0, 255
+		if cem == 13 {
+			da, a = astcBitTransferSigned(v[7], v[6])
+		}
+		if dr + dg + db >= 0 {
+			e0 = [4]int{r, g, b, a}
+			e1 = [4]int{r + dr, g + dg, b + db, a + da}
+		} else {
+			e0 = astcBlueContract(r + dr, g + dg, b + db, a + da)
+			e1 = astcBlueContract(r, g, b, a)
+		}
+	case 10: // RGB, base and scale, with two alphas
+		e0 = [4]int{(v[0] * v[3]) >> 8, (v[1] * v[3]) >> 8, (v[2] * v[3]) >> 8, v[4]}
+		e1 = [4]int{v[0], v[1], v[2], v[5]}
+	default:
+		return e0, e1, false
+	}
+
+	for c := 0; c < 4; c++ {
+		e0[c] = int(clamp255(e0[c]))
+		e1[c] = int(clamp255(e1[c]))
+	}
+	return e0, e1, true
+}
+
+func astcHash(seed uint32) uint32 {
+	seed ^= seed >> 15
+	seed *= 0xEEDE0891
+	seed ^= seed >> 5
+	seed += seed << 16
+	seed ^= seed >> 7
+	seed ^= seed >> 3
+	seed ^= seed << 6
+	seed ^= seed >> 17
+	return seed
+}
+
+// Returns the partition of a texel
+func astcPartition(seed, x, y, partitions int, smallBlock bool) int {
+	if partitions == 1 { return 0 }
+	if smallBlock {
+		x <<= 1
+		y <<= 1
+	}
+
+	seed += (partitions - 1) * 1024
+	rnum := astcHash(uint32(seed))
+
+	var s [8]int
+	for i := range s {
+		v := int(rnum >> (4 * i)) & 0xF
+		s[i] = v * v
+	}
+
+	var sh1, sh2 int
+	if seed & 1 != 0 {
+		sh1 = 4
+		if seed & 2 == 0 {
+			sh1 = 5
+		}
+		sh2 = 5
+		if partitions == 3 {
+			sh2 = 6
+		}
+	} else {
+		sh1 = 5
+		if partitions == 3 {
+			sh1 = 6
+		}
+		sh2 = 4
+		if seed & 2 == 0 {
+			sh2 = 5
+		}
+	}
+
+	a := ((s[0] >> sh1) * x) + ((s[1] >> sh2) * y) + int(rnum >> 14)
+	b := ((s[2] >> sh1) * x) + ((s[3] >> sh2) * y) + int(rnum >> 10)
+	c := ((s[4] >> sh1) * x) + ((s[5] >> sh2) * y) + int(rnum >> 6)
+	d := ((s[6] >> sh1) * x) + ((s[7] >> sh2) * y) + int(rnum >> 2)
+	a, b, c, d = a & 0x3F, b & 0x3F, c & 0x3F, d & 0x3F
+
+	if partitions < 4 {
+		d = 0
+	}
+	if partitions < 3 {
+		c = 0
+	}
+
+	switch {
+	case a >= b && a >= c && a >= d: return 0
+	case b >= c && b >= d: return 1
+	case c >= d: return 2
+	default: return 3
+	}
+}
+
+// Decodes a bw x bh ASTC block. Pixels are stored row major in dst
+func decodeASTC(block []byte, dst [][4]uint8, bw, bh int, srgb bool) {
+	if !decodeASTCBlock(block, dst, bw, bh, srgb) {
+		for i := range dst {
+			dst[i] = astcErrorColor
+		}
+	}
+}
+
+func decodeASTCBlock(block []byte, dst [][4]uint8, bw, bh int, srgb bool) bool {
+	r := newBlockReader(block)
+	mode := r.bitsAt(0, 11)
+	if mode & 0x1FF == 0x1FC {
+		return decodeASTCVoidExtent(r, dst)
+	}
+
+	wx, wy, dualPlane, weightRange, ok := astcBlockMode(mode)
+	if !ok || wx > bw || wy > bh { return false }
+	planes := 1
+	if dualPlane {
+		planes = 2
+	}
+	numWeights := wx * wy * planes
+	if numWeights > 64 { return false }
+	weightBits := astcSequenceBits(numWeights, weightRange)
+	if weightBits < 24 || weightBits > 96 { return false }
+
+	partitions := r.bitsAt(11, 2) + 1
+	if partitions == 4 && dualPlane { return false }
+
+	// The color endpoint modes. When the partitions use different modes, the extra bits are stored below the weights
+	belowWeights := 128 - weightBits
+	var cems [4]int
+	colorStart := 17
+	partitionIndex := 0
+	if partitions == 1 {
+		cems[0] = r.bitsAt(13, 4)
+	} else {
+		partitionIndex = r.bitsAt(13, 10)
+		colorStart = 29
+		cem := r.bitsAt(23, 6)
+		if cem & 3 == 0 {
+			for i := 0; i < partitions; i++ {
+				cems[i] = cem >> 2
+			}
+		} else {
+			extra := (3 * partitions) - 4
+			belowWeights -= extra
+			cem |= r.bitsAt(belowWeights, extra) << 6
+
+			base := (cem & 3) - 1
+			pos := 2
+			for i := 0; i < partitions; i++ {
+				cems[i] = (((cem >> pos) & 1) + base) << 2
+				pos++
+			}
+			for i := 0; i < partitions; i++ {
+				cems[i] |= (cem >> pos) & 3
+				pos += 2
+			}
+		}
+	}
+
+	plane2Component := -1
+	if dualPlane {
+		belowWeights -= 2
+		plane2Component = r.bitsAt(belowWeights, 2)
+	}
+
+	// The color values use the largest range that fits in the remaining bits
+	numColors := 0
+	for i := 0; i < partitions; i++ {
+		numColors += ((cems[i] >> 2) + 1) * 2
+	}
+	if numColors > 18 { return false }
+	colorRange := -1
+	for q := len(astcRanges) - 1; q >= astcMinColorRange; q-- {
+		if astcSequenceBits(numColors, q) <= belowWeights - colorStart {
+			colorRange = q
+			break
+		}
+	}
+	if colorRange < 0 { return false }
+
+	colors := astcDecodeSequence(r, colorStart, belowWeights, numColors, colorRange)
+	for i := range colors {
+		colors[i] = astcUnquantizeColor(colors[i], colorRange)
+	}
+	var endpoints [4][2][4]int
+	for i := 0; i < partitions; i++ {
+		n := ((cems[i] >> 2) + 1) * 2
+		e0, e1, ok := astcEndpoints(cems[i], colors[:n])
+		if !ok { return false }
+		colors = colors[n:]
+
+		for c := 0; c < 4; c++ {
+			// Endpoints are expanded to 16 bits before interpolation
+			if srgb {
+				e0[c], e1[c] = (e0[c] << 8) | 0x80, (e1[c] << 8) | 0x80
+			} else {
+				e0[c], e1[c] = e0[c] * 257, e1[c] * 257
+			}
+		}
+		endpoints[i] = [2][4]int{e0, e1}
+	}
+
+	// The weights are stored bit reversed, starting from the top of the block
+	reversed := blockReader{lo: bits.Reverse64(r.hi), hi: bits.Reverse64(r.lo)}
+	weights := astcDecodeSequence(reversed, 0, weightBits, numWeights, weightRange)
+	for i := range weights {
+		weights[i] = astcUnquantizeWeight(weights[i], weightRange)
+	}
+	weight := func(x, y, plane int) int {
+		if x >= wx || y >= wy { return 0 }
+		return weights[(((y * wx) + x) * planes) + plane]
+	}
+
+	// The weight grid is bilinearly infilled to the block size
+	ds := (1024 + (bw / 2)) / (bw - 1)
+	dt := (1024 + (bh / 2)) / (bh - 1)
+	smallBlock := bw * bh < 31
+	for t := 0; t < bh; t++ {
+		for s := 0; s < bw; s++ {
+			gs := ((ds * s * (wx - 1)) + 32) >> 6
+			gt := ((dt * t * (wy - 1)) + 32) >> 6
+			js, fs := gs >> 4, gs & 0xF
+			jt, ft := gt >> 4, gt & 0xF
+			w11 := ((fs * ft) + 8) >> 4
+			w10 := ft - w11
+			w01 := fs - w11
+			w00 := 16 - fs - ft + w11
+
+			var planeWeights [2]int
+			for p := 0; p < planes; p++ {
+				planeWeights[p] = ((weight(js, jt, p) * w00) + (weight(js + 1, jt, p) * w01) +
+					(weight(js, jt + 1, p) * w10) + (weight(js + 1, jt + 1, p) * w11) + 8) >> 4
+			}
+
+			e := endpoints[astcPartition(partitionIndex, s, t, partitions, smallBlock)]
+			i := (t * bw) + s
+			for c := 0; c < 4; c++ {
+				w := planeWeights[0]
+				if c == plane2Component {
+					w = planeWeights[1]
+				}
+				v := ((e[0][c] * (64 - w)) + (e[1][c] * w) + 32) >> 6
+				dst[i][c] = uint8(v >> 8)
+			}
+		}
+	}
+	return true
+}
+
+// Decodes a block which is a single color
+func decodeASTCVoidExtent(r blockReader, dst [][4]uint8) bool {
+	if r.bitsAt(9, 1) == 1 { return false } // HDR
+	if r.bitsAt(10, 2) != 3 { return false }
+
+	// The extent coordinates are either all ones or must describe a valid rectangle
+	minS, maxS := r.bitsAt(12, 13), r.bitsAt(25, 13)
+	minT, maxT := r.bitsAt(38, 13), r.bitsAt(51, 13)
+	allOnes := minS == 0x1FFF && maxS == 0x1FFF && minT == 0x1FFF && maxT == 0x1FFF
+	if !allOnes && (minS >= maxS || minT >= maxT) { return false }
+
+	var color [4]uint8
+	for c := 0; c < 4; c++ {
+		color[c] = uint8(r.bitsAt(64 + (c * 16), 16) >> 8)
+	}
+	for i := range dst {
+		dst[i] = color
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package glitch
+
+import (
+	"testing"
+)
+
+func TestASTCTritsAndQuints(t *testing.T) {
+	// Every combination of trits and quints must be reachable
+	trits := make(map[[5]int]bool)
+	for T := 0; T < 256; T++ {
+		trits[astcTrits(T)] = true
+	}
+	if len(trits) != 243 {
+		t.Fatalf("Expected 243 trit combinations, got %d", len(trits))
+	}
+
+	quints := make(map[[3]int]bool)
+	for Q := 0; Q < 128; Q++ {
+		quints[astcQuints(Q)] = true
+	}
+	if len(quints) != 125 {
+		t.Fatalf("Expected 125 quint combinations, got %d", len(quints))
+	}
+}
+
+func TestASTCUnquantize(t *testing.T) {
+	levels := []int{2, 3, 4, 5, 6, 8, 10, 12, 16, 20, 24, 32, 40, 48, 64, 80, 96, 128, 160, 192, 256}
+	for q, n := range levels {
+		values := make(map[int]bool)
+		for v := 0; v < n; v++ {
+			values[astcUnquantizeColor(v, q)] = true
+		}
+		if q >= astcMinColorRange && (len(values) != n || !values[0] || !values[255]) {
+			t.Fatalf("Color range %d: bad unquantized values %v", n, values)
+		}
+	}
+
+	for q, n := range levels[:12] {
+		values := make(map[int]bool)
+		for v := 0; v < n; v++ {
+			values[astcUnquantizeWeight(v, q)] = true
+		}
+		if len(values) != n || !values[0] || !values[64] {
+			t.Fatalf("Weight range %d: bad unquantized values %v", n, values)
+		}
+	}
+}
+
+func TestDecodeASTCVoidExtent(t *testing.T) {
+	var w blockWriter
+	w.write(0xDFC, 12)
+	for i := 0; i < 4; i++ {
+		w.write(0x1FFF, 13)
+	}
+	for _, v := range []int{0xFFFF, 0x8000, 0x0000, 0xFFFF} {
+		w.write(v, 16)
+	}
+
+	img := &CompressedImage{
+		Format: CompressedFormat{CompressionASTC, false, 6, 6},
+		Width: 7,
+		Height: 7,
+	}
+	data := make([]byte, 0, 4 * 16)
+	for i := 0; i < 4; i++ {
+		data = append(data, w.bytes()...)
+	}
+	img.Levels = [][]byte{data}
+
+	rgba, err := img.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []int{0, 6 * 4, len(rgba.Pix) - 4} {
+		if got := rgba.Pix[p:p+4]; got[0] != 255 || got[1] != 128 || got[2] != 0 || got[3] != 255 {
+			t.Fatalf("Wrong pixel at %d: %v", p, got)
+		}
+	}
+}
+
+func TestDecodeASTCSinglePartition(t *testing.T) {
+	// A 4x4 grid of 2 bit weights with black and white RGB endpoints (CEM 8) stored in 8 bits each
+	var w blockWriter
+	w.write(0x42, 11)
+	w.write(0, 2)
+	w.write(8, 4)
+	for _, v := range []int{0, 255, 0, 255, 0, 255} {
+		w.write(v, 8)
+	}
+	// Weights are stored bit reversed from the top of the block
+	for i := 0; i < 16; i++ {
+		for b := 0; b < 2; b++ {
+			w.setBit(127 - ((i * 2) + b), ((i % 4) >> b) & 1)
+		}
+	}
+
+	pixels := make([][4]uint8, 16)
+	decodeASTC(w.bytes(), pixels, 4, 4, false)
+	want := []uint8{0, 84, 171, 255}
+	for i, p := range pixels {
+		v := want[i % 4]
+		if p != [4]uint8{v, v, v, 255} {
+			t.Fatalf("Wrong pixel %d: %v", i, p)
+		}
+	}
+}
+
+func TestDecodeASTCErrorBlock(t *testing.T) {
+	// Block mode 0 is reserved
+	pixels := make([][4]uint8, 16)
+	decodeASTC(make([]byte, 16), pixels, 4, 4, false)
+	for i, p := range pixels {
+		if p != astcErrorColor {
+			t.Fatalf("Wrong pixel %d: %v", i, p)
+		}
+	}
+}
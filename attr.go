@@ -86,4 +86,5 @@ const (
 	ColorRGBA
 	TexCoordXY
 	// TexCoordXYZ // Is this a thing?
+	TangentXYZW // The tangent in xyz, and the sign of the bitangent in w (See Mesh.RecalculateTangents)
 )
@@ -0,0 +1,338 @@
+package glitch
+
+import (
+	"encoding/binary"
+)
+
+// --------------------------------------------------------------------------------
+// - BC7
+// --------------------------------------------------------------------------------
+// https://learn.microsoft.com/en-us/windows/win32/direct3d11/bc7-format-mode-reference
+
+// The layout of each BC7 mode
+type bc7Mode struct {
+	subsets int
+	partitionBits int
+	rotationBits int
+	indexSelectionBits int
+	colorBits, alphaBits int
+	endpointPBits bool // A unique p-bit per endpoint
+	sharedPBits bool // A p-bit shared by both endpoints of a subset
+	indexBits, indexBits2 int
+}
+
+var bc7Modes = [8]bc7Mode{
+	{3, 4, 0, 0, 4, 0, true, false, 3, 0},
+	{2, 6, 0, 0, 6, 0, false, true, 3, 0},
+	{3, 6, 0, 0, 5, 0, false, false, 2, 0},
+	{2, 6, 0, 0, 7, 0, true, false, 2, 0},
+	{1, 0, 2, 1, 5, 6, false, false, 2, 3},
+	{1, 0, 2, 0, 7, 8, false, false, 2, 2},
+	{1, 0, 0, 0, 7, 7, true, false, 4, 0},
+	{2, 6, 0, 0, 5, 5, true, false, 2, 0},
+}
+
+// Two subset partitions. Bit i is set if pixel i is in the second subset
+var bc7Partitions2 = [64]uint16{
+	0xCCCC, 0x8888, 0xEEEE, 0xECC8, 0xC880, 0xFEEC, 0xFEC8, 0xEC80,
+	0xC800, 0xFFEC, 0xFE80, 0xE800, 0xFFE8, 0xFF00, 0xFFF0, 0xF000,
+	0xF710, 0x008E, 0x7100, 0x08CE, 0x008C, 0x7310, 0x3100, 0x8CCE,
+	0x088C, 0x3110, 0x6666, 0x366C, 0x17E8, 0x0FF0, 0x718E, 0x399C,
+	0xAAAA, 0xF0F0, 0x5A5A, 0x33CC, 0x3C3C, 0x55AA, 0x9696, 0xA55A,
+	0x73CE, 0x13C8, 0x324C, 0x3BDC, 0x6996, 0xC33C, 0x9966, 0x0660,
+	0x0272, 0x04E4, 0x4E40, 0x2720, 0xC936, 0x936C, 0x39C6, 0x639C,
+	0x9336, 0x9CC6, 0x817E, 0xE718, 0xCCF0, 0x0FCC, 0x7744, 0xEE22,
+}
+
+// Three subset partitions, the subset of each pixel
+var bc7Partitions3 = [64][16]uint8{
+	{0, 0, 1, 1, 0, 0, 1, 1, 0, 2, 2, 1, 2, 2, 2, 2},
+	{0, 0, 0, 1, 0, 0, 1, 1, 2, 2, 1, 1, 2, 2, 2, 1},
+	{0, 0, 0, 0, 2, 0, 0, 1, 2, 2, 1, 1, 2, 2, 1, 1},
+	{0, 2, 2, 2, 0, 0, 2, 2, 0, 0, 1, 1, 0, 1, 1, 1},
+	{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 2, 2, 1, 1, 2, 2},
+	{0, 0, 1, 1, 0, 0, 1, 1, 0, 0, 2, 2, 0, 0, 2, 2},
+	{0, 0, 2, 2, 0, 0, 2, 2, 1, 1, 1, 1, 1, 1, 1, 1},
+	{0, 0, 1, 1, 0, 0, 1, 1, 2, 2, 1, 1, 2, 2, 1, 1},
+	{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2},
+	{0, 0, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1, 2, 2, 2, 2},
+	{0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 2, 2, 2, 2},
+	{0, 0, 1, 2, 0, 0, 1, 2, 0, 0, 1, 2, 0, 0, 1, 2},
+	{0, 1, 1, 2, 0, 1, 1, 2, 0, 1, 1, 2, 0, 1, 1, 2},
+	{0, 1, 2, 2, 0, 1, 2, 2, 0, 1, 2, 2, 0, 1, 2, 2},
+	{0, 0, 1, 1, 0, 1, 1, 2, 1, 1, 2, 2, 1, 2, 2, 2},
+	{0, 0, 1, 1, 2, 0, 0, 1, 2, 2, 0, 0, 2, 2, 2, 0},
+	{0, 0, 0, 1, 0, 0, 1, 1, 0, 1, 1, 2, 1, 1, 2, 2},
+	{0, 1, 1, 1, 0, 0, 1, 1, 2, 0, 0, 1, 2, 2, 0, 0},
+	{0, 0, 0, 0, 1, 1, 2, 2, 1, 1, 2, 2, 1, 1, 2, 2},
+	{0, 0, 2, 2, 0, 0, 2, 2, 0, 0, 2, 2, 1, 1, 1, 1},
+	{0, 1, 1, 1, 0, 1, 1, 1, 0, 2, 2, 2, 0, 2, 2, 2},
+	{0, 0, 0, 1, 0, 0, 0, 1, 2, 2, 2, 1, 2, 2, 2, 1},
+	{0, 0, 0, 0, 0, 0, 1, 1, 0, 1, 2, 2, 0, 1, 2, 2},
+	{0, 0, 0, 0, 1, 1, 0, 0, 2, 2, 1, 0, 2, 2, 1, 0},
+	{0, 1, 2, 2, 0, 1, 2, 2, 0, 0, 1, 1, 0, 0, 0, 0},
+	{0, 0, 1, 2, 0, 0, 1, 2, 1, 1, 2, 2, 2, 2, 2, 2},
+	{0, 1, 1, 0, 1, 2, 2, 1, 1, 2, 2, 1, 0, 1, 1, 0},
+	{0, 0, 0, 0, 0, 1, 1, 0, 1, 2, 2, 1, 1, 2, 2, 1},
+	{0, 0, 2, 2, 1, 1, 0, 2, 1, 1, 0, 2, 0, 0, 2, 2},
+	{0, 1, 1, 0, 0, 1, 1, 0, 2, 0, 0, 2, 2, 2, 2, 2},
+	{0, 0, 1, 1, 0, 1, 2, 2, 0, 1, 2, 2, 0, 0, 1, 1},
+	{0, 0, 0, 0, 2, 0, 0, 0, 2, 2, 1, 1, 2, 2, 2, 1},
+	{0, 0, 0, 0, 0, 0, 0, 2, 1, 1, 2, 2, 1, 2, 2, 2},
+	{0, 2, 2, 2, 0, 0, 2, 2, 0, 0, 1, 2, 0, 0, 1, 1},
+	{0, 0, 1, 1, 0, 0, 1, 2, 0, 0, 2, 2, 0, 2, 2, 2},
+	{0, 1, 2, 0, 0, 1, 2, 0, 0, 1, 2, 0, 0, 1, 2, 0},
+	{0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 0, 0, 0, 0},
+	{0, 1, 2, 0, 1, 2, 0, 1, 2, 0, 1, 2, 0, 1, 2, 0},
+	{0, 1, 2, 0, 2, 0, 1, 2, 1, 2, 0, 1, 0, 1, 2, 0},
+	{0, 0, 1, 1, 2, 2, 0, 0, 1, 1, 2, 2, 0, 0, 1, 1},
+	{0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 0, 0, 0, 0, 1, 1},
+	{0, 1, 0, 1, 0, 1, 0, 1, 2, 2, 2, 2, 2, 2, 2, 2},
+	{0, 0, 0, 0, 0, 0, 0, 0, 2, 1, 2, 1, 2, 1, 2, 1},
+	{0, 0, 2, 2, 1, 1, 2, 2, 0, 0, 2, 2, 1, 1, 2, 2},
+	{0, 0, 2, 2, 0, 0, 1, 1, 0, 0, 2, 2, 0, 0, 1, 1},
+	{0, 2, 2, 0, 1, 2, 2, 1, 0, 2, 2, 0, 1, 2, 2, 1},
+	{0, 1, 0, 1, 2, 2, 2, 2, 2, 2, 2, 2, 0, 1, 0, 1},
+	{0, 0, 0, 0, 2, 1, 2, 1, 2, 1, 2, 1, 2, 1, 2, 1},
+	{0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 2, 2, 2, 2},
+	{0, 2, 2, 2, 0, 1, 1, 1, 0, 2, 2, 2, 0, 1, 1, 1},
+	{0, 0, 0, 2, 1, 1, 1, 2, 0, 0, 0, 2, 1, 1, 1, 2},
+	{0, 0, 0, 0, 2, 1, 1, 2, 2, 1, 1, 2, 2, 1, 1, 2},
+	{0, 2, 2, 2, 0, 1, 1, 1, 0, 1, 1, 1, 0, 2, 2, 2},
+	{0, 0, 0, 2, 1, 1, 1, 2, 1, 1, 1, 2, 0, 0, 0, 2},
+	{0, 1, 1, 0, 0, 1, 1, 0, 0, 1, 1, 0, 2, 2, 2, 2},
+	{0, 0, 0, 0, 0, 0, 0, 0, 2, 1, 1, 2, 2, 1, 1, 2},
+	{0, 1, 1, 0, 0, 1, 1, 0, 2, 2, 2, 2, 2, 2, 2, 2},
+	{0, 0, 2, 2, 0, 0, 1, 1, 0, 0, 1, 1, 0, 0, 2, 2},
+	{0, 0, 2, 2, 1, 1, 2, 2, 1, 1, 2, 2, 0, 0, 2, 2},
+	{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 1, 1, 2},
+	{0, 0, 0, 2, 0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0, 1},
+	{0, 2, 2, 2, 1, 2, 2, 2, 0, 2, 2, 2, 1, 2, 2, 2},
+	{0, 1, 0, 1, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2},
+	{0, 1, 1, 1, 2, 0, 1, 1, 2, 2, 0, 1, 2, 2, 2, 0},
+}
+
+// The anchor pixel of the second subset of each two subset partition. The first subset's anchor is always pixel 0
+var bc7Anchors2 = [64]uint8{
+	15, 15, 15, 15, 15, 15, 15, 15,
+	15, 15, 15, 15, 15, 15, 15, 15,
+	15, 2, 8, 2, 2, 8, 8, 15,
+	2, 8, 2, 2, 8, 8, 2, 2,
+	15, 15, 6, 8, 2, 8, 15, 15,
+	2, 8, 2, 2, 2, 15, 15, 6,
+	6, 2, 6, 8, 15, 15, 2, 2,
+	15, 15, 15, 15, 15, 2, 2, 15,
+}
+
+// The anchor pixels of the second and third subsets of each three subset partition
+var bc7Anchors3 = [2][64]uint8{
+	{
+		3, 3, 15, 15, 8, 3, 15, 15,
+		8, 8, 6, 6, 6, 5, 3, 3,
+		3, 3, 8, 15, 3, 3, 6, 10,
+		5, 8, 8, 6, 8, 5, 15, 15,
+		8, 15, 3, 5, 6, 10, 8, 15,
+		15, 3, 15, 5, 15, 15, 15, 15,
+		3, 15, 5, 5, 5, 8, 5, 10,
+		5, 10, 8, 13, 15, 12, 3, 3,
+	},
+	{
+		15, 8, 8, 3, 15, 15, 3, 8,
+		15, 15, 15, 15, 15, 15, 15, 8,
+		15, 8, 15, 3, 15, 8, 15, 8,
+		3, 15, 6, 10, 15, 15, 10, 8,
+		15, 3, 15, 10, 10, 8, 9, 10,
+		6, 15, 8, 15, 3, 6, 6, 8,
+		15, 3, 15, 15, 15, 15, 15, 15,
+		15, 15, 15, 15, 3, 15, 15, 8,
+	},
+}
+
+// Interpolation weights for 2, 3 and 4 bit indices
+var bc7Weights = [5][]int{
+	2: {0, 21, 43, 64},
+	3: {0, 9, 18, 27, 37, 46, 55, 64},
+	4: {0, 4, 9, 13, 17, 21, 26, 30, 34, 38, 43, 47, 51, 55, 60, 64},
+}
+
+// Reads a 128 bit block from least to most significant bit
+type blockReader struct {
+	lo, hi uint64
+	pos int
+}
+
+func newBlockReader(block []byte) blockReader {
+	return blockReader{
+		lo: binary.LittleEndian.Uint64(block[0:]),
+		hi: binary.LittleEndian.Uint64(block[8:]),
+	}
+}
+
+// Returns the n bits at pos (n <= 32). Bits past the end of the block read as 0
+func (r *blockReader) bitsAt(pos, n int) int {
+	if n <= 0 || pos >= 128 { return 0 }
+	var v uint64
+	if pos >= 64 {
+		v = r.hi >> (pos - 64)
+	} else {
+		v = r.lo >> pos
+		if pos > 0 {
+			v |= r.hi << (64 - pos)
+		}
+	}
+	return int(v & ((1 << n) - 1))
+}
+
+func (r *blockReader) read(n int) int {
+	v := r.bitsAt(r.pos, n)
+	r.pos += n
+	return v
+}
+
+// Returns the subset of a pixel in a BC7 partition
+func bc7Subset(subsets, partition, pixel int) int {
+	switch subsets {
+	case 2:
+		return int(bc7Partitions2[partition] >> pixel) & 1
+	case 3:
+		return int(bc7Partitions3[partition][pixel])
+	}
+	return 0
+}
+
+// Returns true if the pixel is the anchor of its subset, whose index is stored with one less bit
+func bc7IsAnchor(subsets, partition, pixel int) bool {
+	if pixel == 0 { return true }
+	switch subsets {
+	case 2:
+		return pixel == int(bc7Anchors2[partition])
+	case 3:
+		return pixel == int(bc7Anchors3[0][partition]) || pixel == int(bc7Anchors3[1][partition])
+	}
+	return false
+}
+
+// Decodes a BC7 block. Pixels are stored row major in dst
+func decodeBC7(block []byte, dst *[16][4]uint8) {
+	r := newBlockReader(block)
+
+	modeIndex := 0
+	for modeIndex < 8 && r.read(1) == 0 {
+		modeIndex++
+	}
+	if modeIndex >= 8 {
+		// Reserved mode, which decodes to transparent black
+		*dst = [16][4]uint8{}
+		return
+	}
+	mode := bc7Modes[modeIndex]
+
+	partition := r.read(mode.partitionBits)
+	rotation := r.read(mode.rotationBits)
+	indexSelection := r.read(mode.indexSelectionBits)
+
+	// Endpoints are stored channel by channel: every red value, then every green value, etc
+	var endpoints [6][4]int
+	numEndpoints := mode.subsets * 2
+	for c := 0; c < 3; c++ {
+		for e := 0; e < numEndpoints; e++ {
+			endpoints[e][c] = r.read(mode.colorBits)
+		}
+	}
+	for e := 0; e < numEndpoints; e++ {
+		if mode.alphaBits > 0 {
+			endpoints[e][3] = r.read(mode.alphaBits)
+		}
+	}
+
+	// Expand the endpoints to 8 bits, appending the p-bit first if there is one
+	colorBits, alphaBits := mode.colorBits, mode.alphaBits
+	var pBits [6]int
+	if mode.endpointPBits {
+		for e := 0; e < numEndpoints; e++ {
+			pBits[e] = r.read(1)
+		}
+	} else if mode.sharedPBits {
+		for s := 0; s < mode.subsets; s++ {
+			p := r.read(1)
+			pBits[s * 2] = p
+			pBits[(s * 2) + 1] = p
+		}
+	}
+	if mode.endpointPBits || mode.sharedPBits {
+		for e := 0; e < numEndpoints; e++ {
+			for c := 0; c < 4; c++ {
+				endpoints[e][c] = (endpoints[e][c] << 1) | pBits[e]
+			}
+		}
+		colorBits++
+		if alphaBits > 0 {
+			alphaBits++
+		}
+	}
+	for e := 0; e < numEndpoints; e++ {
+		for c := 0; c < 3; c++ {
+			endpoints[e][c] = bc7Expand(endpoints[e][c], colorBits)
+		}
+		if alphaBits > 0 {
+			endpoints[e][3] = bc7Expand(endpoints[e][3], alphaBits)
+		} else {
+			endpoints[e][3] = 255
+		}
+	}
+
+	// Read the indices. The anchor of each subset has an implicit 0 high bit
+	var indices, indices2 [16]int
+	for i := 0; i < 16; i++ {
+		n := mode.indexBits
+		if bc7IsAnchor(mode.subsets, partition, i) {
+			n--
+		}
+		indices[i] = r.read(n)
+	}
+	if mode.indexBits2 > 0 {
+		for i := 0; i < 16; i++ {
+			n := mode.indexBits2
+			if i == 0 {
+				n--
+			}
+			indices2[i] = r.read(n)
+		}
+	}
+
+	for i := 0; i < 16; i++ {
+		subset := bc7Subset(mode.subsets, partition, i)
+		e0, e1 := endpoints[subset * 2], endpoints[(subset * 2) + 1]
+
+		colorIndex, colorBits := indices[i], mode.indexBits
+		alphaIndex, alphaBits := indices[i], mode.indexBits
+		if mode.indexBits2 > 0 {
+			alphaIndex, alphaBits = indices2[i], mode.indexBits2
+			if indexSelection == 1 {
+				colorIndex, colorBits, alphaIndex, alphaBits = alphaIndex, alphaBits, colorIndex, colorBits
+			}
+		}
+
+		var pixel [4]int
+		cw := bc7Weights[colorBits][colorIndex]
+		for c := 0; c < 3; c++ {
+			pixel[c] = bc7Interpolate(e0[c], e1[c], cw)
+		}
+		pixel[3] = bc7Interpolate(e0[3], e1[3], bc7Weights[alphaBits][alphaIndex])
+
+		if rotation > 0 {
+			pixel[3], pixel[rotation - 1] = pixel[rotation - 1], pixel[3]
+		}
+		dst[i] = [4]uint8{uint8(pixel[0]), uint8(pixel[1]), uint8(pixel[2]), uint8(pixel[3])}
+	}
+}
+
+// Expands an n bit value to 8 bits by replicating its high bits
+func bc7Expand(v, n int) int {
+	v = v << (8 - n)
+	return v | (v >> n)
+}
+
+func bc7Interpolate(e0, e1, weight int) int {
+	return (((64 - weight) * e0) + (weight * e1) + 32) >> 6
+}
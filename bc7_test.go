@@ -0,0 +1,110 @@
+package glitch
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// Builds a 128 bit block from least to most significant bit
+type blockWriter struct {
+	lo, hi uint64
+	pos int
+}
+
+func (w *blockWriter) write(v, n int) {
+	for i := 0; i < n; i++ {
+		w.setBit(w.pos, (v >> i) & 1)
+		w.pos++
+	}
+}
+
+func (w *blockWriter) setBit(pos, bit int) {
+	if pos < 64 {
+		w.lo |= uint64(bit) << pos
+	} else {
+		w.hi |= uint64(bit) << (pos - 64)
+	}
+}
+
+func (w *blockWriter) bytes() []byte {
+	block := make([]byte, 16)
+	binary.LittleEndian.PutUint64(block[0:], w.lo)
+	binary.LittleEndian.PutUint64(block[8:], w.hi)
+	return block
+}
+
+func TestBC7PartitionAnchors(t *testing.T) {
+	for p := 0; p < 64; p++ {
+		if bc7Subset(2, p, 0) != 0 || bc7Subset(3, p, 0) != 0 {
+			t.Fatalf("Partition %d: pixel 0 isn't in the first subset", p)
+		}
+		if bc7Subset(2, p, int(bc7Anchors2[p])) != 1 {
+			t.Fatalf("Partition %d: two subset anchor isn't in the second subset", p)
+		}
+		for s := 0; s < 2; s++ {
+			if bc7Subset(3, p, int(bc7Anchors3[s][p])) != s + 1 {
+				t.Fatalf("Partition %d: three subset anchor %d isn't in its subset", p, s + 1)
+			}
+		}
+	}
+}
+
+func TestDecodeBC7Mode6(t *testing.T) {
+	// Black to white, with each pixel using its own index (the anchor pixel 0 only has 3 bits)
+	var w blockWriter
+	w.write(1 << 6, 7)
+	for c := 0; c < 4; c++ {
+		w.write(0, 7)
+		w.write(0x7F, 7)
+	}
+	w.write(0, 1)
+	w.write(1, 1)
+	for i := 0; i < 16; i++ {
+		if i == 0 {
+			w.write(0, 3)
+		} else {
+			w.write(i, 4)
+		}
+	}
+
+	var pixels [16][4]uint8
+	decodeBC7(w.bytes(), &pixels)
+	for i, p := range pixels {
+		v := uint8(bc7Interpolate(0, 255, bc7Weights[4][i]))
+		if p != [4]uint8{v, v, v, v} {
+			t.Fatalf("Wrong pixel %d: %v", i, p)
+		}
+	}
+}
+
+func TestDecodeBC7Mode1(t *testing.T) {
+	// Partition 0 splits the block into red on the left and blue on the right, every index is 0
+	// The shared p-bits are set, so the zero channels expand to 2
+	var w blockWriter
+	w.write(1 << 1, 2)
+	w.write(0, 6)
+	endpoints := [3][4]int{
+		{0x3F, 0x3F, 0, 0}, // Red
+		{0, 0, 0, 0}, // Green
+		{0, 0, 0x3F, 0x3F}, // Blue
+	}
+	for c := range endpoints {
+		for _, v := range endpoints[c] {
+			w.write(v, 6)
+		}
+	}
+	w.write(1, 1)
+	w.write(1, 1)
+
+	var pixels [16][4]uint8
+	decodeBC7(w.bytes(), &pixels)
+	for i, p := range pixels {
+		want := [4]uint8{255, 2, 2, 255}
+		if i % 4 >= 2 {
+			want = [4]uint8{2, 2, 255, 255}
+		}
+		if p != want {
+			t.Fatalf("Wrong pixel %d: %v", i, p)
+		}
+	}
+}
@@ -0,0 +1,898 @@
+package glitch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"math/bits"
+	"runtime"
+
+	"github.com/faiface/mainthread"
+	"github.com/unitoftime/gl"
+)
+
+// The block compression scheme of a compressed texture
+type CompressionKind uint8
+const (
+	CompressionBC1 CompressionKind = iota // DXT1 (RGB)
+	CompressionBC1A // DXT1 (RGB with 1 bit alpha)
+	CompressionBC2 // DXT3
+	CompressionBC3 // DXT5
+	CompressionBC4 // RGTC1 (single channel)
+	CompressionBC5 // RGTC2 (two channel)
+	CompressionBC7 // BPTC
+	CompressionETC2 // ETC2 RGB (Also decodes ETC1)
+	CompressionETC2A1 // ETC2 RGB with punchthrough alpha
+	CompressionETC2A8 // ETC2 RGB with EAC alpha
+	CompressionASTC
+)
+
+func (k CompressionKind) String() string {
+	switch k {
+	case CompressionBC1: return "BC1"
+	case CompressionBC1A: return "BC1A"
+	case CompressionBC2: return "BC2"
+	case CompressionBC3: return "BC3"
+	case CompressionBC4: return "BC4"
+	case CompressionBC5: return "BC5"
+	case CompressionBC7: return "BC7"
+	case CompressionETC2: return "ETC2"
+	case CompressionETC2A1: return "ETC2A1"
+	case CompressionETC2A8: return "ETC2A8"
+	case CompressionASTC: return "ASTC"
+	default: return "Unknown"
+	}
+}
+
+type CompressedFormat struct {
+	Kind CompressionKind
+	SRGB bool
+	BlockWidth, BlockHeight int // 4x4 for everything but ASTC
+}
+
+// Returns the number of bytes in a single block
+func (f CompressedFormat) BlockSize() int {
+	switch f.Kind {
+	case CompressionBC1, CompressionBC1A, CompressionBC4, CompressionETC2, CompressionETC2A1:
+		return 8
+	default:
+		return 16
+	}
+}
+
+// Returns the number of bytes needed for a w x h image. Sizes too large to fit in an int return math.MaxInt
+func (f CompressedFormat) DataSize(w, h int) int {
+	blocks := func(size, blockSize int) uint64 {
+		if size < 1 || blockSize < 1 { return 1 }
+		return (uint64(size) + uint64(blockSize) - 1) / uint64(blockSize)
+	}
+
+	hi1, n := bits.Mul64(blocks(w, f.BlockWidth), blocks(h, f.BlockHeight))
+	hi2, n := bits.Mul64(n, uint64(f.BlockSize()))
+	if hi1 != 0 || hi2 != 0 || n > math.MaxInt {
+		return math.MaxInt
+	}
+	return int(n)
+}
+
+// The largest width or height, and the most mipmap levels, that compressed images can have
+const (
+	maxCompressedSize = 1 << 16
+	maxCompressedLevels = 17 // A full mip chain of a maxCompressedSize image
+)
+
+// Returns an error if the dimensions or level count are empty or too large to be a real texture
+func checkCompressedSize(width, height, levels int) error {
+	if width < 1 || height < 1 || width > maxCompressedSize || height > maxCompressedSize {
+		return fmt.Errorf("invalid size %dx%d", width, height)
+	}
+	if levels < 1 || levels > maxCompressedLevels {
+		return fmt.Errorf("invalid level count %d", levels)
+	}
+	return nil
+}
+
+// These come from the S3TC, RGTC, BPTC, ETC2, and ASTC extensions and aren't exported by the gl package
+const (
+	glCOMPRESSED_RGB_S3TC_DXT1 = 0x83F0
+	glCOMPRESSED_RGBA_S3TC_DXT1 = 0x83F1
+	glCOMPRESSED_RGBA_S3TC_DXT3 = 0x83F2
+	glCOMPRESSED_RGBA_S3TC_DXT5 = 0x83F3
+	glCOMPRESSED_SRGB_S3TC_DXT1 = 0x8C4C
+	glCOMPRESSED_SRGB_ALPHA_S3TC_DXT1 = 0x8C4D
+	glCOMPRESSED_SRGB_ALPHA_S3TC_DXT3 = 0x8C4E
+	glCOMPRESSED_SRGB_ALPHA_S3TC_DXT5 = 0x8C4F
+	glCOMPRESSED_RED_RGTC1 = 0x8DBB
+	glCOMPRESSED_RG_RGTC2 = 0x8DBD
+	glCOMPRESSED_RGBA_BPTC_UNORM = 0x8E8C
+	glCOMPRESSED_SRGB_ALPHA_BPTC_UNORM = 0x8E8D
+	glCOMPRESSED_RGB8_ETC2 = 0x9274
+	glCOMPRESSED_SRGB8_ETC2 = 0x9275
+	glCOMPRESSED_RGB8_PUNCHTHROUGH_ALPHA1_ETC2 = 0x9276
+	glCOMPRESSED_SRGB8_PUNCHTHROUGH_ALPHA1_ETC2 = 0x9277
+	glCOMPRESSED_RGBA8_ETC2_EAC = 0x9278
+	glCOMPRESSED_SRGB8_ALPHA8_ETC2_EAC = 0x9279
+	glCOMPRESSED_RGBA_ASTC_4x4 = 0x93B0 // The other block sizes follow in order, see astcBlockSizes
+	glCOMPRESSED_SRGB8_ALPHA8_ASTC_4x4 = 0x93D0
+
+	glTEXTURE_MAX_LEVEL = 0x813D
+)
+
+// ASTC block sizes in the order of their GL enums
+var astcBlockSizes = [][2]int{
+	{4, 4}, {5, 4}, {5, 5}, {6, 5}, {6, 6}, {8, 5}, {8, 6},
+	{8, 8}, {10, 5}, {10, 6}, {10, 8}, {10, 10}, {12, 10}, {12, 12},
+}
+
+// Returns the GL internal format, or false if there isn't one
+func (f CompressedFormat) glFormat() (gl.Enum, bool) {
+	pick := func(linear, srgb gl.Enum) (gl.Enum, bool) {
+		if f.SRGB {
+			return srgb, true
+		}
+		return linear, true
+	}
+
+	switch f.Kind {
+	case CompressionBC1:
+		return pick(glCOMPRESSED_RGB_S3TC_DXT1, glCOMPRESSED_SRGB_S3TC_DXT1)
+	case CompressionBC1A:
+		return pick(glCOMPRESSED_RGBA_S3TC_DXT1, glCOMPRESSED_SRGB_ALPHA_S3TC_DXT1)
+	case CompressionBC2:
+		return pick(glCOMPRESSED_RGBA_S3TC_DXT3, glCOMPRESSED_SRGB_ALPHA_S3TC_DXT3)
+	case CompressionBC3:
+		return pick(glCOMPRESSED_RGBA_S3TC_DXT5, glCOMPRESSED_SRGB_ALPHA_S3TC_DXT5)
+	case CompressionBC4:
+		return glCOMPRESSED_RED_RGTC1, !f.SRGB
+	case CompressionBC5:
+		return glCOMPRESSED_RG_RGTC2, !f.SRGB
+	case CompressionBC7:
+		return pick(glCOMPRESSED_RGBA_BPTC_UNORM, glCOMPRESSED_SRGB_ALPHA_BPTC_UNORM)
+	case CompressionETC2:
+		return pick(glCOMPRESSED_RGB8_ETC2, glCOMPRESSED_SRGB8_ETC2)
+	case CompressionETC2A1:
+		return pick(glCOMPRESSED_RGB8_PUNCHTHROUGH_ALPHA1_ETC2, glCOMPRESSED_SRGB8_PUNCHTHROUGH_ALPHA1_ETC2)
+	case CompressionETC2A8:
+		return pick(glCOMPRESSED_RGBA8_ETC2_EAC, glCOMPRESSED_SRGB8_ALPHA8_ETC2_EAC)
+	case CompressionASTC:
+		for i, size := range astcBlockSizes {
+			if size[0] == f.BlockWidth && size[1] == f.BlockHeight {
+				return pick(gl.Enum(glCOMPRESSED_RGBA_ASTC_4x4 + i), gl.Enum(glCOMPRESSED_SRGB8_ALPHA8_ASTC_4x4 + i))
+			}
+		}
+	}
+	return 0, false
+}
+
+// A block compressed image and its mipmaps, as loaded from a KTX2 or DDS container
+// Note: The blocks are uploaded as-is, so they should be premultiplied when they are authored
+type CompressedImage struct {
+	Format CompressedFormat
+	Width, Height int
+	Levels [][]byte // The data of each mipmap level, starting with the full size image
+}
+
+// The WebGL extensions which add the compressed formats. WebGL only reports a format once its extension is enabled
+var compressedTextureExtensions = []string{
+	"WEBGL_compressed_texture_s3tc",
+	"WEBGL_compressed_texture_s3tc_srgb",
+	"EXT_texture_compression_rgtc",
+	"EXT_texture_compression_bptc",
+	"WEBGL_compressed_texture_etc",
+	"WEBGL_compressed_texture_astc",
+}
+
+// The compressed formats that the driver reports it supports
+// Note: Must be called on the mainthread
+var supportedCompressedFormats map[gl.Enum]bool
+func compressedFormatSupported(format gl.Enum) bool {
+	if supportedCompressedFormats == nil {
+		supportedCompressedFormats = make(map[gl.Enum]bool)
+
+		for _, ext := range compressedTextureExtensions {
+			enableExtension(ext)
+		}
+
+		for _, f := range compressedTextureFormats() {
+			supportedCompressedFormats[f] = true
+		}
+	}
+	return supportedCompressedFormats[format]
+}
+
+// Creates a texture from a compressed image. If the driver supports the format, the blocks are uploaded directly.
+// If it doesn't, the image is decoded on the CPU and uploaded as a regular texture (see CompressedImage.Decode).
+// Note: Mipmaps can't be generated for compressed textures, so config.Mipmap only takes effect if the image contains mipmap levels
+func NewCompressedTexture(img *CompressedImage, config TextureConfig) (*Texture, error) {
+	if len(img.Levels) <= 0 {
+		return nil, fmt.Errorf("NewCompressedTexture: image has no data")
+	}
+	if err := checkCompressedSize(img.Width, img.Height, len(img.Levels)); err != nil {
+		return nil, fmt.Errorf("NewCompressedTexture: %w", err)
+	}
+	for i, level := range img.Levels {
+		w, h := mipSize(img.Width, i), mipSize(img.Height, i)
+		if len(level) < img.Format.DataSize(w, h) {
+			return nil, fmt.Errorf("NewCompressedTexture: level %d is too small", i)
+		}
+	}
+
+	glFormat, ok := img.Format.glFormat()
+	supported := false
+	if ok {
+		mainthread.Call(func() {
+			supported = compressedFormatSupported(glFormat)
+		})
+	}
+
+	if !supported {
+		rgba, err := img.Decode()
+		if err != nil {
+			return nil, fmt.Errorf("NewCompressedTexture: %s not supported by driver: %w", img.Format.Kind, err)
+		}
+		if img.Format.SRGB {
+			config.Format = FormatSRGBA8
+		}
+		return NewTextureExt(rgba, config), nil
+	}
+
+	config.Mipmap = config.Mipmap && len(img.Levels) > 1
+	t := &Texture{
+		width: img.Width,
+		height: img.Height,
+		config: config,
+		compressed: true,
+	}
+
+	mainthread.Call(func() {
+		t.texture = gl.CreateTexture()
+		gl.BindTexture(gl.TEXTURE_2D, t.texture)
+
+		for i, level := range img.Levels {
+			w, h := mipSize(img.Width, i), mipSize(img.Height, i)
+			size := img.Format.DataSize(w, h)
+			compressedTexImage2D(i, glFormat, w, h, level[:size])
+		}
+		gl.TexParameteri(gl.TEXTURE_2D, glTEXTURE_MAX_LEVEL, len(img.Levels) - 1)
+
+		t.applyConfig()
+	})
+
+	runtime.SetFinalizer(t, (*Texture).delete)
+
+	return t, nil
+}
+
+func mipSize(size, level int) int {
+	size = size >> level
+	if size < 1 {
+		return 1
+	}
+	return size
+}
+
+// Loads a KTX2 or DDS file (based on its magic bytes)
+func DecodeCompressedImage(r io.Reader) (*CompressedImage, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(data, ktx2Identifier) {
+		return decodeKTX2(data)
+	} else if bytes.HasPrefix(data, []byte("DDS ")) {
+		return decodeDDS(data)
+	}
+	return nil, fmt.Errorf("DecodeCompressedImage: unknown container format")
+}
+
+// --------------------------------------------------------------------------------
+// - KTX2
+// --------------------------------------------------------------------------------
+// https://registry.khronos.org/KTX/specs/2.0/ktxspec.v2.html
+var ktx2Identifier = []byte{0xAB, 0x4B, 0x54, 0x58, 0x20, 0x32, 0x30, 0xBB, 0x0D, 0x0A, 0x1A, 0x0A}
+
+func DecodeKTX2(r io.Reader) (*CompressedImage, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return decodeKTX2(data)
+}
+
+func decodeKTX2(data []byte) (*CompressedImage, error) {
+	const headerSize = 12 + (9 * 4) + (4 * 4) + (2 * 8) // Identifier, header, index
+	if len(data) < headerSize || !bytes.HasPrefix(data, ktx2Identifier) {
+		return nil, fmt.Errorf("DecodeKTX2: invalid header")
+	}
+
+	le := binary.LittleEndian
+	vkFormat := le.Uint32(data[12:])
+	width := int(le.Uint32(data[20:]))
+	height := int(le.Uint32(data[24:]))
+	depth := le.Uint32(data[28:])
+	layerCount := le.Uint32(data[32:])
+	faceCount := le.Uint32(data[36:])
+	levelCount := int(le.Uint32(data[40:]))
+	supercompression := le.Uint32(data[44:])
+
+	if depth > 1 || layerCount > 1 || faceCount > 1 {
+		return nil, fmt.Errorf("DecodeKTX2: only single 2D images are supported")
+	}
+	if supercompression != 0 {
+		return nil, fmt.Errorf("DecodeKTX2: supercompression scheme %d not supported", supercompression)
+	}
+
+	format, ok := vkCompressedFormat(vkFormat)
+	if !ok {
+		return nil, fmt.Errorf("DecodeKTX2: vkFormat %d not supported", vkFormat)
+	}
+
+	if levelCount < 1 {
+		levelCount = 1
+	}
+	if err := checkCompressedSize(width, height, levelCount); err != nil {
+		return nil, fmt.Errorf("DecodeKTX2: %w", err)
+	}
+	levelIndex := data[headerSize:]
+	if len(levelIndex) < levelCount * 24 {
+		return nil, fmt.Errorf("DecodeKTX2: invalid level index")
+	}
+
+	img := &CompressedImage{
+		Format: format,
+		Width: width,
+		Height: height,
+		Levels: make([][]byte, levelCount),
+	}
+	for i := 0; i < levelCount; i++ {
+		offset := le.Uint64(levelIndex[i*24:])
+		length := le.Uint64(levelIndex[i*24+8:])
+		// Checked separately so that crafted values can't overflow the sum
+		if offset > uint64(len(data)) || length > uint64(len(data)) - offset {
+			return nil, fmt.Errorf("DecodeKTX2: level %d out of bounds", i)
+		}
+		img.Levels[i] = data[offset:offset+length]
+	}
+
+	return img, nil
+}
+
+// Converts a VkFormat into a compressed format
+func vkCompressedFormat(vkFormat uint32) (CompressedFormat, bool) {
+	f := func(kind CompressionKind, srgb bool) (CompressedFormat, bool) {
+		return CompressedFormat{kind, srgb, 4, 4}, true
+	}
+
+	switch vkFormat {
+	case 131: return f(CompressionBC1, false)
+	case 132: return f(CompressionBC1, true)
+	case 133: return f(CompressionBC1A, false)
+	case 134: return f(CompressionBC1A, true)
+	case 135: return f(CompressionBC2, false)
+	case 136: return f(CompressionBC2, true)
+	case 137: return f(CompressionBC3, false)
+	case 138: return f(CompressionBC3, true)
+	case 139: return f(CompressionBC4, false)
+	case 141: return f(CompressionBC5, false)
+	case 145: return f(CompressionBC7, false)
+	case 146: return f(CompressionBC7, true)
+	case 147: return f(CompressionETC2, false)
+	case 148: return f(CompressionETC2, true)
+	case 149: return f(CompressionETC2A1, false)
+	case 150: return f(CompressionETC2A1, true)
+	case 151: return f(CompressionETC2A8, false)
+	case 152: return f(CompressionETC2A8, true)
+	}
+
+	// VK_FORMAT_ASTC_4x4_UNORM_BLOCK through VK_FORMAT_ASTC_12x12_SRGB_BLOCK alternate between unorm and srgb
+	if vkFormat >= 157 && vkFormat <= 184 {
+		idx := int(vkFormat - 157)
+		size := astcBlockSizes[idx / 2]
+		return CompressedFormat{CompressionASTC, idx % 2 == 1, size[0], size[1]}, true
+	}
+
+	return CompressedFormat{}, false
+}
+
+// --------------------------------------------------------------------------------
+// - DDS
+// --------------------------------------------------------------------------------
+// https://learn.microsoft.com/en-us/windows/win32/direct3ddds/dx-graphics-dds-pguide
+func DecodeDDS(r io.Reader) (*CompressedImage, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDDS(data)
+}
+
+func decodeDDS(data []byte) (*CompressedImage, error) {
+	const headerSize = 4 + 124 // Magic, header
+	if len(data) < headerSize || !bytes.HasPrefix(data, []byte("DDS ")) {
+		return nil, fmt.Errorf("DecodeDDS: invalid header")
+	}
+
+	le := binary.LittleEndian
+	height := int(le.Uint32(data[12:]))
+	width := int(le.Uint32(data[16:]))
+	flags := le.Uint32(data[8:])
+	mipCount := int(le.Uint32(data[28:]))
+	pixelFlags := le.Uint32(data[80:])
+	fourCC := string(data[84:88])
+
+	const ddpfFourCC = 0x4
+	if pixelFlags & ddpfFourCC == 0 {
+		return nil, fmt.Errorf("DecodeDDS: only compressed DDS files are supported")
+	}
+
+	offset := headerSize
+	var format CompressedFormat
+	var ok bool
+	if fourCC == "DX10" {
+		if len(data) < headerSize + 20 {
+			return nil, fmt.Errorf("DecodeDDS: invalid DX10 header")
+		}
+		dxgiFormat := le.Uint32(data[headerSize:])
+		arraySize := le.Uint32(data[headerSize+12:])
+		if arraySize > 1 {
+			return nil, fmt.Errorf("DecodeDDS: texture arrays are not supported")
+		}
+		format, ok = dxgiCompressedFormat(dxgiFormat)
+		if !ok {
+			return nil, fmt.Errorf("DecodeDDS: DXGI format %d not supported", dxgiFormat)
+		}
+		offset += 20
+	} else {
+		format, ok = fourCCCompressedFormat(fourCC)
+		if !ok {
+			return nil, fmt.Errorf("DecodeDDS: FourCC %q not supported", fourCC)
+		}
+	}
+
+	// The mip count is only valid if its flag is set
+	const ddsdMipMapCount = 0x20000
+	if flags & ddsdMipMapCount == 0 || mipCount < 1 {
+		mipCount = 1
+	}
+	if err := checkCompressedSize(width, height, mipCount); err != nil {
+		return nil, fmt.Errorf("DecodeDDS: %w", err)
+	}
+
+	img := &CompressedImage{
+		Format: format,
+		Width: width,
+		Height: height,
+		Levels: make([][]byte, 0, mipCount),
+	}
+	for i := 0; i < mipCount; i++ {
+		size := format.DataSize(mipSize(width, i), mipSize(height, i))
+		if size > len(data) - offset {
+			return nil, fmt.Errorf("DecodeDDS: level %d out of bounds", i)
+		}
+		img.Levels = append(img.Levels, data[offset:offset+size])
+		offset += size
+	}
+
+	return img, nil
+}
+
+func fourCCCompressedFormat(fourCC string) (CompressedFormat, bool) {
+	switch fourCC {
+	case "DXT1": return CompressedFormat{CompressionBC1A, false, 4, 4}, true
+	case "DXT2", "DXT3": return CompressedFormat{CompressionBC2, false, 4, 4}, true
+	case "DXT4", "DXT5": return CompressedFormat{CompressionBC3, false, 4, 4}, true
+	case "ATI1", "BC4U": return CompressedFormat{CompressionBC4, false, 4, 4}, true
+	case "ATI2", "BC5U": return CompressedFormat{CompressionBC5, false, 4, 4}, true
+	}
+	return CompressedFormat{}, false
+}
+
+func dxgiCompressedFormat(dxgiFormat uint32) (CompressedFormat, bool) {
+	f := func(kind CompressionKind, srgb bool) (CompressedFormat, bool) {
+		return CompressedFormat{kind, srgb, 4, 4}, true
+	}
+
+	switch dxgiFormat {
+	case 71: return f(CompressionBC1A, false)
+	case 72: return f(CompressionBC1A, true)
+	case 74: return f(CompressionBC2, false)
+	case 75: return f(CompressionBC2, true)
+	case 77: return f(CompressionBC3, false)
+	case 78: return f(CompressionBC3, true)
+	case 80: return f(CompressionBC4, false)
+	case 83: return f(CompressionBC5, false)
+	case 98: return f(CompressionBC7, false)
+	case 99: return f(CompressionBC7, true)
+	}
+	return CompressedFormat{}, false
+}
+
+// --------------------------------------------------------------------------------
+// - CPU Decoding
+// --------------------------------------------------------------------------------
+
+// Decodes the full size level of the image on the CPU
+func (c *CompressedImage) Decode() (*image.RGBA, error) {
+	// Adapts the decoders of the 4x4 formats
+	block4x4 := func(decode func(block []byte, dst *[16][4]uint8)) func([]byte, [][4]uint8) {
+		return func(block []byte, dst [][4]uint8) { decode(block, (*[16][4]uint8)(dst)) }
+	}
+
+	blockWidth, blockHeight := 4, 4
+	var decodeBlock func(block []byte, dst [][4]uint8)
+	switch c.Format.Kind {
+	case CompressionBC1:
+		decodeBlock = block4x4(func(block []byte, dst *[16][4]uint8) { decodeBC1(block, dst, false, false) })
+	case CompressionBC1A:
+		decodeBlock = block4x4(func(block []byte, dst *[16][4]uint8) { decodeBC1(block, dst, true, false) })
+	case CompressionBC2:
+		decodeBlock = block4x4(decodeBC2)
+	case CompressionBC3:
+		decodeBlock = block4x4(decodeBC3)
+	case CompressionBC4:
+		decodeBlock = block4x4(decodeBC4)
+	case CompressionBC5:
+		decodeBlock = block4x4(decodeBC5)
+	case CompressionBC7:
+		decodeBlock = block4x4(decodeBC7)
+	case CompressionETC2:
+		decodeBlock = block4x4(func(block []byte, dst *[16][4]uint8) { decodeETC2(block, dst, false) })
+	case CompressionETC2A1:
+		decodeBlock = block4x4(func(block []byte, dst *[16][4]uint8) { decodeETC2(block, dst, true) })
+	case CompressionETC2A8:
+		decodeBlock = block4x4(decodeETC2A8)
+	case CompressionASTC:
+		if _, ok := c.Format.glFormat(); !ok {
+			return nil, fmt.Errorf("invalid ASTC block size %dx%d", c.Format.BlockWidth, c.Format.BlockHeight)
+		}
+		blockWidth, blockHeight = c.Format.BlockWidth, c.Format.BlockHeight
+		decodeBlock = func(block []byte, dst [][4]uint8) { decodeASTC(block, dst, blockWidth, blockHeight, c.Format.SRGB) }
+	default:
+		return nil, fmt.Errorf("no CPU decoder for %s", c.Format.Kind)
+	}
+
+	if err := checkCompressedSize(c.Width, c.Height, len(c.Levels)); err != nil {
+		return nil, err
+	}
+	if len(c.Levels[0]) < c.Format.DataSize(c.Width, c.Height) {
+		return nil, fmt.Errorf("image data too small")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, c.Width, c.Height))
+	data := c.Levels[0]
+	blockSize := c.Format.BlockSize()
+	blocksX := (c.Width + blockWidth - 1) / blockWidth
+	blocksY := (c.Height + blockHeight - 1) / blockHeight
+
+	pixels := make([][4]uint8, blockWidth * blockHeight)
+	for by := 0; by < blocksY; by++ {
+		for bx := 0; bx < blocksX; bx++ {
+			start := ((by * blocksX) + bx) * blockSize
+			decodeBlock(data[start:start+blockSize], pixels)
+
+			for py := 0; py < blockHeight; py++ {
+				for px := 0; px < blockWidth; px++ {
+					x := (bx * blockWidth) + px
+					y := (by * blockHeight) + py
+					if x >= c.Width || y >= c.Height { continue }
+					i := img.PixOffset(x, y)
+					copy(img.Pix[i:i+4], pixels[(py * blockWidth) + px][:])
+				}
+			}
+		}
+	}
+	return img, nil
+}
+
+// Expands a 565 color to 888
+func rgb565(c uint16) [3]int {
+	r := int(c >> 11) & 0x1F
+	g := int(c >> 5) & 0x3F
+	b := int(c) & 0x1F
+	return [3]int{(r << 3) | (r >> 2), (g << 2) | (g >> 4), (b << 3) | (b >> 2)}
+}
+
+// Decodes a BC1 color block. Pixels are stored row major in dst
+func decodeBC1(block []byte, dst *[16][4]uint8, alpha bool, forceFourColor bool) {
+	c0 := binary.LittleEndian.Uint16(block[0:])
+	c1 := binary.LittleEndian.Uint16(block[2:])
+	indices := binary.LittleEndian.Uint32(block[4:])
+
+	a := rgb565(c0)
+	b := rgb565(c1)
+	var palette [4][4]uint8
+	palette[0] = [4]uint8{uint8(a[0]), uint8(a[1]), uint8(a[2]), 255}
+	palette[1] = [4]uint8{uint8(b[0]), uint8(b[1]), uint8(b[2]), 255}
+	if c0 > c1 || forceFourColor {
+		for i := 0; i < 3; i++ {
+			palette[2][i] = uint8(((2 * a[i]) + b[i]) / 3)
+			palette[3][i] = uint8((a[i] + (2 * b[i])) / 3)
+		}
+		palette[2][3] = 255
+		palette[3][3] = 255
+	} else {
+		for i := 0; i < 3; i++ {
+			palette[2][i] = uint8((a[i] + b[i]) / 2)
+		}
+		palette[2][3] = 255
+		if alpha {
+			palette[3] = [4]uint8{0, 0, 0, 0}
+		} else {
+			palette[3] = [4]uint8{0, 0, 0, 255}
+		}
+	}
+
+	for i := 0; i < 16; i++ {
+		dst[i] = palette[(indices >> (2 * i)) & 0x3]
+	}
+}
+
+func decodeBC2(block []byte, dst *[16][4]uint8) {
+	decodeBC1(block[8:], dst, false, true)
+	alpha := binary.LittleEndian.Uint64(block[0:])
+	for i := 0; i < 16; i++ {
+		dst[i][3] = uint8((alpha >> (4 * i)) & 0xF) * 17
+	}
+}
+
+func decodeBC3(block []byte, dst *[16][4]uint8) {
+	decodeBC1(block[8:], dst, false, true)
+	var alpha [16]uint8
+	decodeBC4Channel(block[0:8], &alpha)
+	for i := 0; i < 16; i++ {
+		dst[i][3] = alpha[i]
+	}
+}
+
+// Decodes a BC4 block into a single channel. This is the same as a BC3 alpha block
+func decodeBC4Channel(block []byte, dst *[16]uint8) {
+	a0 := int(block[0])
+	a1 := int(block[1])
+	var palette [8]int
+	palette[0] = a0
+	palette[1] = a1
+	if a0 > a1 {
+		for i := 1; i < 7; i++ {
+			palette[i+1] = (((7 - i) * a0) + (i * a1)) / 7
+		}
+	} else {
+		for i := 1; i < 5; i++ {
+			palette[i+1] = (((5 - i) * a0) + (i * a1)) / 5
+		}
+		palette[6] = 0
+		palette[7] = 255
+	}
+
+	// 48 bits of 3 bit indices
+	var bits uint64
+	for i := 0; i < 6; i++ {
+		bits |= uint64(block[2+i]) << (8 * i)
+	}
+	for i := 0; i < 16; i++ {
+		dst[i] = uint8(palette[(bits >> (3 * i)) & 0x7])
+	}
+}
+
+// Note: Single channel data is decoded as (r, 0, 0, 1) which matches how GL samples it
+func decodeBC4(block []byte, dst *[16][4]uint8) {
+	var r [16]uint8
+	decodeBC4Channel(block[0:8], &r)
+	for i := 0; i < 16; i++ {
+		dst[i] = [4]uint8{r[i], 0, 0, 255}
+	}
+}
+
+func decodeBC5(block []byte, dst *[16][4]uint8) {
+	var r, g [16]uint8
+	decodeBC4Channel(block[0:8], &r)
+	decodeBC4Channel(block[8:16], &g)
+	for i := 0; i < 16; i++ {
+		dst[i] = [4]uint8{r[i], g[i], 0, 255}
+	}
+}
+
+// https://registry.khronos.org/DataFormat/specs/1.3/dataformat.1.3.html#ETC2
+var etcModifiers = [8][2]int{
+	{2, 8}, {5, 17}, {9, 29}, {13, 42}, {18, 60}, {24, 80}, {33, 106}, {47, 183},
+}
+
+var etcDistances = [8]int{3, 6, 11, 16, 23, 32, 41, 64}
+
+func clamp255(v int) uint8 {
+	if v < 0 { return 0 }
+	if v > 255 { return 255 }
+	return uint8(v)
+}
+
+func extend4(v uint64) int { return int((v << 4) | v) }
+func extend5(v uint64) int { return int((v << 3) | (v >> 2)) }
+func extend6(v uint64) int { return int((v << 2) | (v >> 4)) }
+func extend7(v uint64) int { return int((v << 1) | (v >> 6)) }
+
+// Decodes an ETC2 RGB block (which includes ETC1). If punchthrough is true, bit 33 is the opaque flag rather than the differential flag.
+// Pixels are stored row major in dst
+func decodeETC2(block []byte, dst *[16][4]uint8, punchthrough bool) {
+	bits := binary.BigEndian.Uint64(block)
+	field := func(hi, lo uint) uint64 {
+		return (bits >> lo) & ((1 << (hi - lo + 1)) - 1)
+	}
+
+	diff := field(33, 33) == 1
+	opaque := true
+	if punchthrough {
+		opaque = diff
+		diff = true // Punchthrough blocks don't have an individual mode
+	}
+
+	// Per pixel index bits. Note: Pixels are stored column major
+	pixelIndex := func(x, y int) int {
+		p := uint(x * 4 + y)
+		msb := (bits >> (16 + p)) & 1
+		lsb := (bits >> p) & 1
+		return int((msb << 1) | lsb)
+	}
+
+	setPaint := func(paint [4][3]int) {
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				idx := pixelIndex(x, y)
+				if !opaque && idx == 2 {
+					dst[(y * 4) + x] = [4]uint8{0, 0, 0, 0}
+					continue
+				}
+				c := paint[idx]
+				dst[(y * 4) + x] = [4]uint8{clamp255(c[0]), clamp255(c[1]), clamp255(c[2]), 255}
+			}
+		}
+	}
+
+	var base1, base2 [3]int
+	if !diff {
+		// Individual mode
+		base1 = [3]int{extend4(field(63, 60)), extend4(field(55, 52)), extend4(field(47, 44))}
+		base2 = [3]int{extend4(field(59, 56)), extend4(field(51, 48)), extend4(field(43, 40))}
+	} else {
+		r, g, b := int(field(63, 59)), int(field(55, 51)), int(field(47, 43))
+		dr, dg, db := int(field(58, 56)), int(field(50, 48)), int(field(42, 40))
+		if dr >= 4 { dr -= 8 }
+		if dg >= 4 { dg -= 8 }
+		if db >= 4 { db -= 8 }
+
+		if r + dr < 0 || r + dr > 31 {
+			// T mode
+			c1 := [3]int{extend4((field(60, 59) << 2) | field(57, 56)), extend4(field(55, 52)), extend4(field(51, 48))}
+			c2 := [3]int{extend4(field(47, 44)), extend4(field(43, 40)), extend4(field(39, 36))}
+			d := etcDistances[(field(35, 34) << 1) | field(32, 32)]
+			setPaint([4][3]int{
+				c1,
+				{c2[0] + d, c2[1] + d, c2[2] + d},
+				c2,
+				{c2[0] - d, c2[1] - d, c2[2] - d},
+			})
+			return
+		} else if g + dg < 0 || g + dg > 31 {
+			// H mode
+			r1, g1, b1 := field(62, 59), (field(58, 56) << 1) | field(52, 52), (field(51, 51) << 3) | field(49, 47)
+			r2, g2, b2 := field(46, 43), field(42, 39), field(38, 35)
+			c1 := [3]int{extend4(r1), extend4(g1), extend4(b1)}
+			c2 := [3]int{extend4(r2), extend4(g2), extend4(b2)}
+			dIdx := (field(34, 34) << 2) | (field(32, 32) << 1)
+			if ((r1 << 8) | (g1 << 4) | b1) >= ((r2 << 8) | (g2 << 4) | b2) {
+				dIdx |= 1
+			}
+			d := etcDistances[dIdx]
+			setPaint([4][3]int{
+				{c1[0] + d, c1[1] + d, c1[2] + d},
+				{c1[0] - d, c1[1] - d, c1[2] - d},
+				{c2[0] + d, c2[1] + d, c2[2] + d},
+				{c2[0] - d, c2[1] - d, c2[2] - d},
+			})
+			return
+		} else if b + db < 0 || b + db > 31 {
+			// Planar mode
+			o := [3]int{
+				extend6(field(62, 57)),
+				extend7((field(56, 56) << 6) | field(54, 49)),
+				extend6((field(48, 48) << 5) | (field(44, 43) << 3) | field(41, 39)),
+			}
+			h := [3]int{
+				extend6((field(38, 34) << 1) | field(32, 32)),
+				extend7(field(31, 25)),
+				extend6(field(24, 19)),
+			}
+			v := [3]int{
+				extend6(field(18, 13)),
+				extend7(field(12, 6)),
+				extend6(field(5, 0)),
+			}
+			for y := 0; y < 4; y++ {
+				for x := 0; x < 4; x++ {
+					var c [4]uint8
+					for i := 0; i < 3; i++ {
+						c[i] = clamp255(((x * (h[i] - o[i])) + (y * (v[i] - o[i])) + (4 * o[i]) + 2) >> 2)
+					}
+					c[3] = 255
+					dst[(y * 4) + x] = c
+				}
+			}
+			return
+		}
+
+		base1 = [3]int{extend5(uint64(r)), extend5(uint64(g)), extend5(uint64(b))}
+		base2 = [3]int{extend5(uint64(r + dr)), extend5(uint64(g + dg)), extend5(uint64(b + db))}
+	}
+
+	// Individual or differential mode
+	table1 := etcModifiers[field(39, 37)]
+	table2 := etcModifiers[field(36, 34)]
+	flip := field(32, 32) == 1
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			base, table := base1, table1
+			if (!flip && x >= 2) || (flip && y >= 2) {
+				base, table = base2, table2
+			}
+
+			idx := pixelIndex(x, y)
+			var modifier int
+			switch idx {
+			case 0: modifier = table[0]
+			case 1: modifier = table[1]
+			case 2: modifier = -table[0]
+			case 3: modifier = -table[1]
+			}
+
+			if !opaque {
+				if idx == 2 {
+					dst[(y * 4) + x] = [4]uint8{0, 0, 0, 0}
+					continue
+				}
+				if idx == 0 {
+					modifier = 0
+				}
+			}
+
+			dst[(y * 4) + x] = [4]uint8{clamp255(base[0] + modifier), clamp255(base[1] + modifier), clamp255(base[2] + modifier), 255}
+		}
+	}
+}
+
+var eacModifiers = [16][8]int{
+	{-3, -6, -9, -15, 2, 5, 8, 14},
+	{-3, -7, -10, -13, 2, 6, 9, 12},
+	{-2, -5, -8, -13, 1, 4, 7, 12},
+	{-2, -4, -6, -13, 1, 3, 5, 12},
+	{-3, -6, -8, -12, 2, 5, 7, 11},
+	{-3, -7, -9, -11, 2, 6, 8, 10},
+	{-4, -7, -8, -11, 3, 6, 7, 10},
+	{-3, -5, -8, -11, 2, 4, 7, 10},
+	{-2, -6, -8, -10, 1, 5, 7, 9},
+	{-2, -5, -8, -10, 1, 4, 7, 9},
+	{-2, -4, -8, -10, 1, 3, 7, 9},
+	{-2, -5, -7, -10, 1, 4, 6, 9},
+	{-3, -4, -7, -10, 2, 3, 6, 9},
+	{-1, -2, -3, -10, 0, 1, 2, 9},
+	{-4, -6, -8, -9, 3, 5, 7, 8},
+	{-3, -5, -7, -9, 2, 4, 6, 8},
+}
+
+// Decodes an ETC2 RGBA block, which is an EAC alpha block followed by an ETC2 RGB block
+func decodeETC2A8(block []byte, dst *[16][4]uint8) {
+	decodeETC2(block[8:16], dst, false)
+
+	bits := binary.BigEndian.Uint64(block[0:8])
+	base := int(bits >> 56)
+	multiplier := int((bits >> 52) & 0xF)
+	table := eacModifiers[(bits >> 48) & 0xF]
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			p := uint(x * 4 + y) // Column major
+			idx := (bits >> (45 - (3 * p))) & 0x7
+			dst[(y * 4) + x][3] = clamp255(base + (table[idx] * multiplier))
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package glitch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestDecodeBC1(t *testing.T) {
+	// Red and blue endpoints, every pixel uses the first endpoint except the last which uses the second
+	block := []byte{0x00, 0xF8, 0x1F, 0x00, 0, 0, 0, 0x40}
+	var pixels [16][4]uint8
+	decodeBC1(block, &pixels, false, false)
+
+	if pixels[0] != [4]uint8{255, 0, 0, 255} {
+		t.Fatalf("Wrong first pixel: %v", pixels[0])
+	}
+	if pixels[15] != [4]uint8{0, 0, 255, 255} {
+		t.Fatalf("Wrong last pixel: %v", pixels[15])
+	}
+}
+
+func TestDecodeETC2Individual(t *testing.T) {
+	// Individual mode, both base colors are 0x88, codeword 0, all pixel indices 0 (+2)
+	block := make([]byte, 8)
+	binary.BigEndian.PutUint64(block, 0x8888880000000000)
+	var pixels [16][4]uint8
+	decodeETC2(block, &pixels, false)
+
+	for i, p := range pixels {
+		if p != [4]uint8{0x8A, 0x8A, 0x8A, 255} {
+			t.Fatalf("Wrong pixel %d: %v", i, p)
+		}
+	}
+}
+
+func TestDecodeDDS(t *testing.T) {
+	header := make([]byte, 128)
+	copy(header, "DDS ")
+	le := binary.LittleEndian
+	le.PutUint32(header[4:], 124)
+	le.PutUint32(header[8:], 0x20000) // Mip count flag
+	le.PutUint32(header[12:], 8) // Height
+	le.PutUint32(header[16:], 8) // Width
+	le.PutUint32(header[28:], 2) // Mip count
+	le.PutUint32(header[80:], 0x4) // FourCC flag
+	copy(header[84:], "DXT1")
+
+	data := append(header, make([]byte, (4 * 8) + 8)...)
+	img, err := DecodeCompressedImage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Format.Kind != CompressionBC1A || img.Width != 8 || img.Height != 8 {
+		t.Fatalf("Wrong image: %v %d %d", img.Format, img.Width, img.Height)
+	}
+	if len(img.Levels) != 2 || len(img.Levels[0]) != 32 || len(img.Levels[1]) != 8 {
+		t.Fatalf("Wrong levels: %d", len(img.Levels))
+	}
+
+	rgba, err := img.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rgba.Bounds().Dx() != 8 {
+		t.Fatalf("Wrong decoded size: %v", rgba.Bounds())
+	}
+}
+
+func TestDecodeDDSMipCountFlag(t *testing.T) {
+	header := make([]byte, 128)
+	copy(header, "DDS ")
+	le := binary.LittleEndian
+	le.PutUint32(header[4:], 124)
+	le.PutUint32(header[12:], 8) // Height
+	le.PutUint32(header[16:], 8) // Width
+	le.PutUint32(header[28:], 4) // Mip count, without its flag
+	le.PutUint32(header[80:], 0x4) // FourCC flag
+	copy(header[84:], "DXT1")
+
+	data := append(header, make([]byte, 4 * 8)...)
+	img, err := DecodeCompressedImage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(img.Levels) != 1 {
+		t.Fatalf("The mip count should be ignored without its flag: %d levels", len(img.Levels))
+	}
+}
+
+func TestDecodeKTX2LevelBounds(t *testing.T) {
+	data := make([]byte, 80 + 24 + 32)
+	copy(data, ktx2Identifier)
+	le := binary.LittleEndian
+	le.PutUint32(data[12:], 131) // BC1
+	le.PutUint32(data[20:], 8) // Width
+	le.PutUint32(data[24:], 8) // Height
+	le.PutUint32(data[40:], 1) // Level count
+
+	le.PutUint64(data[80:], 104) // Offset
+	le.PutUint64(data[88:], 32) // Length
+	img, err := DecodeCompressedImage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(img.Levels) != 1 || len(img.Levels[0]) != 32 {
+		t.Fatalf("Wrong levels: %v", len(img.Levels))
+	}
+
+	// Offset + length overflows back into range
+	le.PutUint64(data[80:], 104)
+	le.PutUint64(data[88:], ^uint64(0) - 50)
+	if _, err := DecodeCompressedImage(bytes.NewReader(data)); err == nil {
+		t.Fatalf("Expected an out of bounds error")
+	}
+}
+
+func TestDataSizeOverflow(t *testing.T) {
+	format := CompressedFormat{CompressionBC1A, false, 4, 4}
+	if size := format.DataSize(0xFFFFFFFF, 0xFFFFFFFF); size <= 0 {
+		t.Fatalf("Size should saturate instead of overflowing: %d", size)
+	}
+	if size := format.DataSize(8, 8); size != 32 {
+		t.Fatalf("Wrong size: %d", size)
+	}
+}
+
+func TestDecodeDDSMalformedHeader(t *testing.T) {
+	newHeader := func(width, height, mipCount uint32) []byte {
+		header := make([]byte, 128 + 32)
+		copy(header, "DDS ")
+		le := binary.LittleEndian
+		le.PutUint32(header[4:], 124)
+		le.PutUint32(header[8:], 0x20000) // Mip count flag
+		le.PutUint32(header[12:], height)
+		le.PutUint32(header[16:], width)
+		le.PutUint32(header[28:], mipCount)
+		le.PutUint32(header[80:], 0x4) // FourCC flag
+		copy(header[84:], "DXT1")
+		return header
+	}
+
+	tests := map[string][]byte{
+		"huge": newHeader(0xFFFFFFFF, 0xFFFFFFFF, 1),
+		"zero width": newHeader(0, 8, 1),
+		"zero height": newHeader(8, 0, 1),
+		"too many levels": newHeader(8, 8, 1000),
+	}
+	for name, data := range tests {
+		if _, err := DecodeCompressedImage(bytes.NewReader(data)); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestDecodeKTX2MalformedHeader(t *testing.T) {
+	newHeader := func(width, height, levelCount uint32) []byte {
+		data := make([]byte, 80 + 24 + 32)
+		copy(data, ktx2Identifier)
+		le := binary.LittleEndian
+		le.PutUint32(data[12:], 131) // BC1
+		le.PutUint32(data[20:], width)
+		le.PutUint32(data[24:], height)
+		le.PutUint32(data[40:], levelCount)
+		le.PutUint64(data[80:], 104) // Offset
+		le.PutUint64(data[88:], 32) // Length
+		return data
+	}
+
+	tests := map[string][]byte{
+		"huge": newHeader(0xFFFFFFFF, 0xFFFFFFFF, 1),
+		"zero width": newHeader(0, 8, 1),
+		"zero height": newHeader(8, 0, 1),
+		"too many levels": newHeader(8, 8, 0xFFFFFFFF),
+	}
+	for name, data := range tests {
+		if _, err := DecodeCompressedImage(bytes.NewReader(data)); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+
+	// An image built by hand is checked before it is decoded
+	img := &CompressedImage{
+		Format: CompressedFormat{CompressionBC1, false, 4, 4},
+		Width: 0xFFFFFFFF,
+		Height: 0xFFFFFFFF,
+		Levels: [][]byte{make([]byte, 32)},
+	}
+	if _, err := img.Decode(); err == nil {
+		t.Fatalf("Expected an error decoding a huge image")
+	}
+}
@@ -21,7 +21,7 @@ import (
 var cpuprofile = flag.String("cpuprofile", "", "write cpu profile to `file`")
 var memprofile = flag.String("memprofile", "", "write memory profile to `file`")
 
-//go:embed gopher.png
+//go:embed gopher.png crate.obj crate.mtl
 var f embed.FS
 func loadImage(path string) (*image.NRGBA, error) {
 	file, err := f.Open(path)
@@ -106,7 +106,10 @@ func runGame() {
 
 	text := atlas.Text("hello world")
 
-	cube := glitch.NewModel(glitch.NewCubeMesh(50), glitch.DefaultMaterial())
+	crate, err := glitch.LoadOBJ(f, "crate.obj", glitch.SmoothTextureConfig(true))
+	if err != nil {
+		panic(err)
+	}
 
 	camera := glitch.NewCameraOrtho()
 	pCam := glitch.NewCamera()
@@ -148,7 +151,9 @@ func runGame() {
 		cubeMat := glitch.Mat4Ident
 		cubeMat = *cubeMat.Translate(0, 0, 0).Rotate(float32(tt), glitch.Vec3{0, 0, 1})
 
-		cube.Draw(diffusePass, cubeMat)
+		for _, model := range crate {
+			model.Draw(diffusePass, cubeMat)
+		}
 
 		mat = glitch.Mat4Ident
 		mat.Translate(0, 0, 0)
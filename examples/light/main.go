@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/unitoftime/glitch"
+	"github.com/unitoftime/glitch/shaders"
+)
+
+func check(err error) {
+	if err != nil { panic(err) }
+}
+
+func main() {
+	glitch.Run(runGame)
+}
+
+func runGame() {
+	win, err := glitch.NewWindow(1920, 1080, "Glitch - 2D Lights", glitch.WindowConfig{
+		Vsync: true,
+	})
+	check(err)
+
+	shader, err := glitch.NewShader(shaders.SpriteShader)
+	check(err)
+	pass := glitch.NewRenderPass(shader)
+
+	lights, err := shaders.NewLightPass(win.Bounds())
+	check(err)
+	lights.TrackWindow(win)
+
+	// A few boxes which cast shadows
+	boxes := []glitch.Rect{
+		glitch.R(400, 300, 550, 450),
+		glitch.R(900, 600, 1000, 900),
+		glitch.R(1300, 200, 1500, 300),
+	}
+	box := glitch.NewSprite(glitch.WhiteTexture(), glitch.R(0, 0, 1, 1))
+
+	camera := glitch.NewCameraOrtho()
+	start := time.Now()
+	for !win.ShouldClose() {
+		if win.Pressed(glitch.KeyBackspace) {
+			win.Close()
+		}
+		t := time.Since(start).Seconds()
+
+		camera.SetOrtho2D(win.Bounds())
+		camera.SetView2D(0, 0, 1.0, 1.0)
+
+		// Scene
+		pass.Clear()
+		for _, b := range boxes {
+			mat := glitch.Mat4Ident
+			mat.Scale(b.W(), b.H(), 1).Translate(b.Min[0], b.Min[1], 0)
+			box.DrawColorMask(pass, mat, glitch.RGBA{0.8, 0.8, 0.8, 1.0})
+		}
+		glitch.Clear(win, glitch.RGBA{0.3, 0.4, 0.5, 1.0})
+		pass.SetUniform("projection", camera.Projection)
+		pass.SetUniform("view", camera.View)
+		pass.Draw(win)
+
+		// Lights
+		lights.Clear()
+		for _, b := range boxes {
+			lights.AddOccluder([]glitch.Vec3{
+				{b.Min[0], b.Min[1], 0},
+				{b.Max[0], b.Min[1], 0},
+				{b.Max[0], b.Max[1], 0},
+				{b.Min[0], b.Max[1], 0},
+			})
+		}
+		lights.AddLight(glitch.Light2D{
+			Position: glitch.Vec2{960 + 500 * float32(math.Cos(t)), 540 + 300 * float32(math.Sin(t))},
+			Color: glitch.RGBA{1.0, 0.9, 0.7, 1.0},
+			Radius: 700,
+			Falloff: 2,
+			Softness: 20,
+		})
+		lights.AddLight(glitch.Light2D{
+			Position: glitch.Vec2{200, 900},
+			Color: glitch.RGBA{0.4, 0.6, 1.0, 1.0},
+			Radius: 1200,
+			Falloff: 1,
+			Angle: math.Pi / 4,
+			Direction: float32(-math.Pi / 4 + 0.3 * math.Sin(t)),
+		})
+		lights.SetCamera(camera.Projection, camera.View)
+		lights.Draw()
+		lights.Composite(win)
+
+		win.Update()
+	}
+}
@@ -0,0 +1,119 @@
+package glitch
+
+// An edge between two positions, ordered so that both directions of the edge match
+type featherEdgeKey struct {
+	a, b Vec2
+}
+
+func newFeatherEdgeKey(a, b Vec2) featherEdgeKey {
+	if a[0] < b[0] || (a[0] == b[0] && a[1] < b[1]) {
+		return featherEdgeKey{a, b}
+	}
+	return featherEdgeKey{b, a}
+}
+
+type featherEdge struct {
+	a, b uint32 // The vertices of the edge
+	normal Vec2 // Points out of the shape
+	count int // The number of triangles which use the edge
+}
+
+type featherCorner struct {
+	normal Vec2 // The sum of the normals of the outside edges which touch the corner
+	count int
+	z float32
+}
+
+// The longest that a feather corner can stretch, relative to the feather width
+const featherMiterLimit = 4
+
+// Adds a strip of triangles around the outside edges of the triangles, which fade out over the feather distance. This anti aliases the edges without needing MSAA.
+// The edges are found by position, so triangles which share an edge don't need to share vertices. Returns the new positions and indices, and the index of the first faded vertex
+func featherMesh(positions []Vec3, inds []uint32, feather float32) ([]Vec3, []uint32, int) {
+	edges := make(map[featherEdgeKey]*featherEdge)
+	order := make([]featherEdgeKey, 0) // Keeps the output deterministic
+	for i := 0; i + 2 < len(inds); i += 3 {
+		tri := [3]uint32{inds[i], inds[i+1], inds[i+2]}
+		if cross2(positions[tri[0]].Vec2(), positions[tri[1]].Vec2(), positions[tri[2]].Vec2()) == 0 {
+			continue // Zero area triangles don't have an inside
+		}
+		for j := 0; j < 3; j++ {
+			a := tri[j]
+			b := tri[(j + 1) % 3]
+			c := tri[(j + 2) % 3]
+
+			pa := positions[a].Vec2()
+			pb := positions[b].Vec2()
+			if pa == pb { continue }
+			key := newFeatherEdgeKey(pa, pb)
+			if e, ok := edges[key]; ok {
+				e.count++
+				continue
+			}
+
+			// The normal faces away from the third corner of the triangle
+			d := pb.Sub(pa)
+			normal := Vec2{-d[1], d[0]}.Scaled(1 / d.Len())
+			toC := positions[c].Vec2().Sub(pa)
+			if (normal[0] * toC[0]) + (normal[1] * toC[1]) > 0 {
+				normal = normal.Scaled(-1)
+			}
+			edges[key] = &featherEdge{a, b, normal, 1}
+			order = append(order, key)
+		}
+	}
+
+	// Sum the normals of the outside edges at each outside corner
+	corners := make(map[Vec2]*featherCorner)
+	cornerOrder := make([]Vec2, 0)
+	for _, key := range order {
+		e := edges[key]
+		if e.count != 1 { continue }
+		for _, v := range []uint32{e.a, e.b} {
+			p := positions[v].Vec2()
+			c, ok := corners[p]
+			if !ok {
+				c = &featherCorner{z: positions[v][2]}
+				corners[p] = c
+				cornerOrder = append(cornerOrder, p)
+			}
+			c.normal = c.normal.Add(e.normal)
+			c.count++
+		}
+	}
+
+	// Move each corner out so that all of its edges are pushed out by the full feather distance
+	start := len(positions)
+	outer := make(map[Vec2]uint32)
+	for _, p := range cornerOrder {
+		c := corners[p]
+		length := c.normal.Len()
+		if length < 1e-6 { continue } // The edges cancel out (Ex: the tip of a zero width spike)
+		dir := c.normal.Scaled(1 / length)
+
+		// Each edge normal makes the same angle with the direction, so the offset is feather / cos(angle)
+		scale := float32(featherMiterLimit)
+		cos := length / float32(c.count)
+		if cos > 1.0 / featherMiterLimit {
+			scale = 1 / cos
+		}
+
+		offset := dir.Scaled(feather * scale)
+		outer[p] = uint32(len(positions))
+		positions = append(positions, Vec3{p[0] + offset[0], p[1] + offset[1], c.z})
+	}
+
+	for _, key := range order {
+		e := edges[key]
+		if e.count != 1 { continue }
+		aOut, okA := outer[positions[e.a].Vec2()]
+		bOut, okB := outer[positions[e.b].Vec2()]
+		if !okA || !okB { continue }
+		inds = append(inds,
+			e.a, e.b, bOut,
+			e.a, bOut, aOut,
+		)
+	}
+
+	return positions, inds, start
+}
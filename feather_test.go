@@ -0,0 +1,37 @@
+package glitch
+
+import (
+	"testing"
+)
+
+func TestFeatherRect(t *testing.T) {
+	g := NewGeomDraw()
+	g.Feather = 1
+	mesh := g.FillRect(R(0, 0, 10, 10))
+
+	// The square corners stay square, so the shape grows by the feather on every side
+	checkArea(t, triangleArea(mesh.positions, mesh.indices), 12 * 12)
+	bounds := mesh.Bounds()
+	if bounds.Min.Sub(Vec3{-1, -1, 0}).Len() > 0.001 || bounds.Max.Sub(Vec3{11, 11, 0}).Len() > 0.001 {
+		t.Fatalf("Unexpected bounds: %v", bounds)
+	}
+
+	faded := 0
+	for _, c := range mesh.colors {
+		if c == (Vec4{0, 0, 0, 0}) {
+			faded++
+		}
+	}
+	if faded != 4 {
+		t.Fatalf("Expected 4 faded vertices, got %d", faded)
+	}
+}
+
+func TestFeatherStroke(t *testing.T) {
+	g := NewGeomDraw()
+	g.Feather = 1
+	mesh := g.Polygon([]Vec3{{0, 0, 0}, {10, 0, 0}, {10, 10, 0}, {0, 10, 0}}, 2)
+
+	// Only the inner and outer edges are feathered, not the edges between the triangles of the line
+	checkArea(t, triangleArea(mesh.positions, mesh.indices), (14 * 14) - (6 * 6))
+}
@@ -1,6 +1,7 @@
 package glitch
 
 import (
+	"fmt"
 	"image"
 	"runtime"
 
@@ -11,20 +12,83 @@ import (
 type Frame struct {
 	fbo gl.Framebuffer
 	tex *Texture
+	textures []*Texture // All of the color attachments in order, textures[0] is tex
+	depthTex *Texture // Only set if the frame was created with DepthTexture
+	depthStencil gl.Renderbuffer // Only set if the depth or stencil buffer is a renderbuffer
+
+	// Multisampled frames draw into renderbuffers on a second framebuffer, which then get resolved into fbo's textures
+	samples int
+	msaaFbo gl.Framebuffer
+	msaaColors []gl.Renderbuffer
+	msaaDepthStencil gl.Renderbuffer
+	resolveMask gl.Enum
+	dirty bool // Set when the multisampled framebuffer is bound, cleared when it is resolved
+
 	mesh *Mesh
 	material Material
 	bounds Rect
+	config FrameConfig
+	window *Window // If set, the frame resizes itself to match this window (See TrackWindow)
+}
+
+// How a frame stores its depth buffer
+type DepthMode uint8
+const (
+	DepthNone DepthMode = iota // No depth buffer
+	DepthRenderbuffer // A 24 bit depth buffer which can be tested against but not sampled
+	DepthTexture // A 24 bit depth texture which can be sampled later (See Frame.DepthTexture)
+)
+
+type FrameConfig struct {
+	Texture TextureConfig // The config (including the format) of the color attachment's texture
+	Attachments []TextureConfig // Additional color attachments, which are written by fragment shader outputs at locations 1, 2, ... (See Frame.Textures). These need WebGL2 on the web
+	Depth DepthMode
+	Stencil bool // If true, an 8 bit stencil buffer is attached. If there is also a depth buffer, they are packed together
+	Samples int // If greater than 1, the frame is multisampled (clamped to the driver's max, and ignored on WebGL1). See Frame.Resolve
+}
+
+// These aren't exported by the gl package
+const (
+	glDEPTH_STENCIL = 0x84F9
+	glDEPTH24_STENCIL8 = 0x88F0
+	glUNSIGNED_INT_24_8 = 0x84FA
+	glDEPTH_STENCIL_ATTACHMENT = 0x821A
+	glMAX_SAMPLES = 0x8D57
+	glMAX_DRAW_BUFFERS = 0x8824
+)
+
+// Returns the attachment point of the i'th color attachment
+func colorAttachment(i int) gl.Enum {
+	return gl.Enum(gl.COLOR_ATTACHMENT0 + i)
 }
 
-// Type? Color, depth, stencil?
+// Creates a frame with only a color attachment. Use NewFrameExt for depth and stencil buffers
 func NewFrame(bounds Rect, smooth bool) *Frame {
+	frame, err := NewFrameExt(bounds, FrameConfig{
+		Texture: SmoothTextureConfig(smooth),
+	})
+	if err != nil {
+		panic(err) // An RGBA8 color attachment is always renderable
+	}
+	return frame
+}
+
+// Creates a frame whose color attachment uses the config's texture format, plus any depth and stencil buffers in the config
+// Returns an error if the driver can't render to that combination (Ex: float formats on drivers without float color buffer support)
+func NewFrameExt(bounds Rect, config FrameConfig) (*Frame, error) {
 	var frame Frame
 	frame.bounds = bounds
+	frame.config = config
+	frame.depthStencil = gl.NoRenderbuffer
+	frame.msaaDepthStencil = gl.NoRenderbuffer
 
-	// Create texture
-	// TODO - Note: I'm passing actual data to the texture object, rather than null. That might be suboptimal. This fills the GPU memory, whereas if I pass null I can just allocate it.
-	img := image.NewRGBA(image.Rect(int(bounds.Min[0]), int(bounds.Min[1]), int(bounds.Max[0]), int(bounds.Max[1])))
-	frame.tex = NewTexture(img, smooth)
+	// Create textures. Their storage is allocated without uploading any data, so their contents start undefined until the frame is cleared
+	width, height := int(bounds.W()), int(bounds.H())
+	frame.tex = newTexture(width, height, nil, config.Texture)
+	frame.textures = []*Texture{frame.tex}
+	for _, texConfig := range config.Attachments {
+		frame.textures = append(frame.textures, newTexture(width, height, nil, texConfig))
+	}
 
 	// Create mesh (in case we want to draw the fbo to another target)
 	// frame.mesh = NewQuadMesh(R(-1, -1, 1, 1), R(0, 1, 1, 0))
@@ -32,43 +96,383 @@ func NewFrame(bounds Rect, smooth bool) *Frame {
 	frame.material = NewSpriteMaterial(frame.tex)
 
 	// frame.tex.Bind(0)///??????
+	floatTargets := false
+	for _, tex := range frame.textures {
+		if tex.config.Format.IsFloat() {
+			floatTargets = true
+		}
+	}
+
+	var status gl.Enum
+	var maxDrawBuffers int
+	floatSupported := true
 	mainthread.Call(func() {
+		// Without GLES3 / WebGL2 there is only one color attachment and no multisampling
+		maxDrawBuffers = 1
+		if framebufferExtSupported() {
+			maxDrawBuffers = getIntParameter(glMAX_DRAW_BUFFERS)
+		}
+		if len(frame.textures) > maxDrawBuffers { return }
+
+		if floatTargets {
+			floatSupported = floatRenderingSupported()
+			if !floatSupported { return }
+		}
+
+		if config.Samples > 1 && framebufferExtSupported() {
+			maxSamples := getIntParameter(glMAX_SAMPLES)
+			frame.samples = config.Samples
+			if frame.samples > maxSamples {
+				frame.samples = maxSamples
+			}
+			if frame.samples <= 1 {
+				frame.samples = 0
+			}
+		}
+
 		frame.fbo = gl.CreateFramebuffer()
 		gl.BindFramebuffer(gl.FRAMEBUFFER, frame.fbo)
-		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, frame.tex.texture, 0)
+		for i, tex := range frame.textures {
+			gl.FramebufferTexture2D(gl.FRAMEBUFFER, colorAttachment(i), gl.TEXTURE_2D, tex.texture, 0)
+		}
+		frame.setDrawBuffers()
+		if frame.samples > 0 {
+			// Only a depth texture needs to be resolved, otherwise the depth and stencil buffers only live on the multisampled framebuffer
+			if config.Depth == DepthTexture {
+				frame.attachDepthTexture(config.Stencil)
+			}
+		} else {
+			frame.attachDepthStencil(config)
+		}
+		status = gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+
+		if status != gl.FRAMEBUFFER_COMPLETE || frame.samples <= 0 { return }
+
+		frame.attachMultisample(config)
+		status = gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
 	})
 
+	if len(frame.textures) > maxDrawBuffers {
+		return nil, fmt.Errorf("NewFrameExt: %d color attachments requested, but the driver only supports %d", len(frame.textures), maxDrawBuffers)
+	}
+	if !floatSupported {
+		return nil, fmt.Errorf("NewFrameExt: float color attachments aren't supported by the driver (needs EXT_color_buffer_float)")
+	}
+
 	runtime.SetFinalizer(&frame, (*Frame).delete)
-	return &frame
+
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		return nil, fmt.Errorf("NewFrameExt: framebuffer incomplete (status: 0x%x)", status)
+	}
+	return &frame, nil
+}
+
+// Returns the renderbuffer format and attachment point for the config's depth and stencil buffers, or false if it doesn't have any
+// Note: Depth textures use the same format, so this also describes the multisampled equivalent of a depth texture
+func depthStencilFormat(config FrameConfig) (gl.Enum, gl.Enum, bool) {
+	depth := config.Depth != DepthNone
+	if depth && config.Stencil {
+		return glDEPTH24_STENCIL8, glDEPTH_STENCIL_ATTACHMENT, true
+	} else if depth {
+		return gl.DEPTH_COMPONENT24, gl.DEPTH_ATTACHMENT, true
+	} else if config.Stencil {
+		return gl.STENCIL_INDEX8, gl.STENCIL_ATTACHMENT, true
+	}
+	return 0, 0, false
+}
+
+// Creates and attaches the depth and stencil buffers to the currently bound framebuffer
+// Note: Must be called on the mainthread
+func (f *Frame) attachDepthStencil(config FrameConfig) {
+	if config.Depth == DepthTexture {
+		f.attachDepthTexture(config.Stencil)
+		return
+	}
+
+	internalFormat, attachment, ok := depthStencilFormat(config)
+	if !ok { return }
+
+	f.depthStencil = gl.CreateRenderbuffer()
+	gl.BindRenderbuffer(gl.RENDERBUFFER, f.depthStencil)
+	renderbufferStorage(internalFormat, f.tex.width, f.tex.height)
+	framebufferRenderbuffer(attachment, f.depthStencil)
+}
+
+// Note: Must be called on the mainthread
+func (f *Frame) attachDepthTexture(stencil bool) {
+	width, height := f.tex.width, f.tex.height
+	f.depthTex = &Texture{
+		width: width,
+		height: height,
+		config: TextureConfig{
+			MinFilter: FilterNearest,
+			MagFilter: FilterNearest,
+			WrapS: WrapClamp,
+			WrapT: WrapClamp,
+		},
+	}
+	f.depthTex.texture = gl.CreateTexture()
+	allocDepthTexture(f.depthTex, stencil)
+	if stencil {
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, glDEPTH_STENCIL_ATTACHMENT, gl.TEXTURE_2D, f.depthTex.texture, 0)
+	} else {
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.TEXTURE_2D, f.depthTex.texture, 0)
+	}
+	f.depthTex.applyConfig()
+	runtime.SetFinalizer(f.depthTex, (*Texture).delete)
+}
+
+// Allocates the storage of a depth texture at the texture's size
+// Note: Must be called on the mainthread
+func allocDepthTexture(t *Texture, stencil bool) {
+	gl.BindTexture(gl.TEXTURE_2D, t.texture)
+	if stencil {
+		gl.TexImage2DFull(gl.TEXTURE_2D, 0, glDEPTH24_STENCIL8, t.width, t.height, glDEPTH_STENCIL, glUNSIGNED_INT_24_8, nil)
+	} else {
+		gl.TexImage2DFull(gl.TEXTURE_2D, 0, gl.DEPTH_COMPONENT24, t.width, t.height, gl.DEPTH_COMPONENT, gl.UNSIGNED_INT, nil)
+	}
+}
+
+// Creates the multisampled framebuffer and its renderbuffers, and leaves it bound
+// Note: Must be called on the mainthread
+func (f *Frame) attachMultisample(config FrameConfig) {
+	width, height := f.tex.width, f.tex.height
+
+	f.msaaFbo = gl.CreateFramebuffer()
+	gl.BindFramebuffer(gl.FRAMEBUFFER, f.msaaFbo)
+
+	f.msaaColors = make([]gl.Renderbuffer, len(f.textures))
+	for i, tex := range f.textures {
+		colorFormat := tex.config.Format.renderbufferFormat()
+		f.msaaColors[i] = gl.CreateRenderbuffer()
+		gl.BindRenderbuffer(gl.RENDERBUFFER, f.msaaColors[i])
+		renderbufferStorageMultisample(f.samples, colorFormat, width, height)
+		framebufferRenderbuffer(colorAttachment(i), f.msaaColors[i])
+	}
+	f.setDrawBuffers()
+	f.resolveMask = gl.COLOR_BUFFER_BIT
+
+	internalFormat, attachment, ok := depthStencilFormat(config)
+	if !ok { return }
+
+	f.msaaDepthStencil = gl.CreateRenderbuffer()
+	gl.BindRenderbuffer(gl.RENDERBUFFER, f.msaaDepthStencil)
+	renderbufferStorageMultisample(f.samples, internalFormat, width, height)
+	framebufferRenderbuffer(attachment, f.msaaDepthStencil)
+
+	if config.Depth == DepthTexture {
+		f.resolveMask |= gl.DEPTH_BUFFER_BIT
+		if config.Stencil {
+			f.resolveMask |= gl.STENCIL_BUFFER_BIT
+		}
+	}
+}
+
+// Enables every color attachment of the currently bound framebuffer for drawing
+// Note: Must be called on the mainthread
+func (f *Frame) setDrawBuffers() {
+	if len(f.textures) <= 1 { return } // Attachment 0 is the default
+	buffers := make([]gl.Enum, len(f.textures))
+	for i := range buffers {
+		buffers[i] = colorAttachment(i)
+	}
+	drawBuffers(buffers)
+}
+
+// Returns the number of samples per pixel, or 0 if the frame isn't multisampled
+func (f *Frame) Samples() int {
+	return f.samples
+}
+
+// Copies the multisampled buffers into the frame's textures. This does nothing for frames that aren't multisampled.
+// This happens automatically when the frame is read from through its own functions (Texture, DepthTexture, Draw, Image), but must be called manually if you have held onto the texture or material and have drawn to the frame since
+func (f *Frame) Resolve() {
+	if f.samples <= 0 { return }
+	f.dirty = false
+
+	w, h := f.tex.width, f.tex.height
+	mainthread.Call(func() {
+		prevFbo := gl.GetBoundFramebuffer()
+		gl.BindFramebuffer(gl.READ_FRAMEBUFFER, f.msaaFbo)
+		gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, f.fbo)
+
+		if len(f.textures) <= 1 {
+			blitFramebuffer(0, 0, w, h, 0, 0, w, h, f.resolveMask, gl.NEAREST)
+		} else {
+			// Blits only copy from one read buffer, so each attachment is resolved separately
+			mask := f.resolveMask
+			buffers := make([]gl.Enum, len(f.textures))
+			for i := range f.textures {
+				for j := range buffers {
+					buffers[j] = gl.NONE
+				}
+				buffers[i] = colorAttachment(i)
+				gl.ReadBuffer(colorAttachment(i))
+				drawBuffers(buffers)
+				blitFramebuffer(0, 0, w, h, 0, 0, w, h, mask, gl.NEAREST)
+				mask = gl.COLOR_BUFFER_BIT // Depth and stencil only need to be copied once
+			}
+			gl.ReadBuffer(gl.COLOR_ATTACHMENT0)
+			f.setDrawBuffers()
+		}
+
+		gl.BindFramebuffer(gl.FRAMEBUFFER, prevFbo)
+	})
+}
+
+func (f *Frame) resolveIfDirty() {
+	if f.dirty {
+		f.Resolve()
+	}
 }
 
 func (f *Frame) Bounds() Rect {
+	f.syncWindowSize()
 	return f.bounds
 }
 
+// Resizes the frame. If the size changes, all of the frame's attachments are reallocated and their contents are lost
+func (f *Frame) Resize(bounds Rect) {
+	oldW, oldH := f.tex.width, f.tex.height
+	f.bounds = bounds
+	f.mesh = NewQuadMesh(bounds, R(0, 1, 1, 0))
+
+	width, height := int(bounds.W()), int(bounds.H())
+	if width == oldW && height == oldH { return }
+
+	for _, tex := range f.textures {
+		tex.resize(width, height)
+	}
+
+	mainthread.Call(func() {
+		if f.depthTex != nil {
+			f.depthTex.width = width
+			f.depthTex.height = height
+			allocDepthTexture(f.depthTex, f.config.Stencil)
+		}
+
+		internalFormat, _, _ := depthStencilFormat(f.config)
+		if f.depthStencil.Valid() {
+			gl.BindRenderbuffer(gl.RENDERBUFFER, f.depthStencil)
+			renderbufferStorage(internalFormat, width, height)
+		}
+
+		if f.samples > 0 {
+			for i, rb := range f.msaaColors {
+				colorFormat := f.textures[i].config.Format.renderbufferFormat()
+				gl.BindRenderbuffer(gl.RENDERBUFFER, rb)
+				renderbufferStorageMultisample(f.samples, colorFormat, width, height)
+			}
+			if f.msaaDepthStencil.Valid() {
+				gl.BindRenderbuffer(gl.RENDERBUFFER, f.msaaDepthStencil)
+				renderbufferStorageMultisample(f.samples, internalFormat, width, height)
+			}
+		}
+	})
+	f.dirty = false
+}
+
+// Makes the frame resize itself to match the window's bounds. The size is checked whenever the frame is bound or its bounds are read. Pass nil to stop tracking
+func (f *Frame) TrackWindow(win *Window) {
+	f.window = win
+	f.syncWindowSize()
+}
+
+func (f *Frame) syncWindowSize() {
+	if f.window == nil { return }
+	bounds := f.window.Bounds()
+	if bounds != f.bounds {
+		f.Resize(bounds)
+	}
+}
+
 func (f *Frame) Texture() *Texture {
+	f.resolveIfDirty()
 	return f.tex
 }
 
+// Returns every color attachment, in the order of their fragment shader output locations
+func (f *Frame) Textures() []*Texture {
+	f.resolveIfDirty()
+	return f.textures
+}
+
+// Returns the depth attachment as a texture, or nil if the frame wasn't created with DepthTexture
+func (f *Frame) DepthTexture() *Texture {
+	f.resolveIfDirty()
+	return f.depthTex
+}
+
+// Reads the frame's texture back from the GPU. Unlike Texture.Image, the rows are flipped so that the image is right side up
+func (f *Frame) Image() (*image.RGBA, error) {
+	img, err := f.Texture().Image()
+	if err != nil {
+		return nil, err
+	}
+
+	stride := img.Stride
+	row := make([]uint8, stride)
+	h := img.Bounds().Dy()
+	for y := 0; y < h/2; y++ {
+		top := img.Pix[y*stride : (y+1)*stride]
+		bot := img.Pix[(h-1-y)*stride : (h-y)*stride]
+		copy(row, top)
+		copy(top, bot)
+		copy(bot, row)
+	}
+	return img, nil
+}
+
+// Reads the frame back from the GPU and writes it to a PNG file
+func (f *Frame) SavePNG(path string) error {
+	img, err := f.Image()
+	if err != nil {
+		return err
+	}
+	return savePNG(path, img)
+}
+
 func (f *Frame) Draw(pass *RenderPass, matrix Mat4) {
 	f.DrawColorMask(pass, matrix, RGBA{1.0, 1.0, 1.0, 1.0})
 }
 func (f *Frame) DrawColorMask(pass *RenderPass, matrix Mat4, mask RGBA) {
 	// pass.SetTexture(0, s.texture)
+	f.resolveIfDirty()
 	pass.Add(f.mesh, matrix, mask, f.material)
 }
 
 func (f *Frame) delete() {
 	mainthread.CallNonBlock(func() {
 		gl.DeleteFramebuffer(f.fbo)
+		if f.depthStencil.Valid() {
+			gl.DeleteRenderbuffer(f.depthStencil)
+		}
+		if f.samples > 0 {
+			gl.DeleteFramebuffer(f.msaaFbo)
+			for _, rb := range f.msaaColors {
+				gl.DeleteRenderbuffer(rb)
+			}
+			if f.msaaDepthStencil.Valid() {
+				gl.DeleteRenderbuffer(f.msaaDepthStencil)
+			}
+		}
 	})
 }
 
+// Binds the frame for drawing. Multisampled frames bind their multisampled framebuffer, which needs to be resolved before the texture is read (See Resolve)
 func (f *Frame) Bind() {
+	f.syncWindowSize()
+
+	fbo := f.fbo
+	if f.samples > 0 {
+		fbo = f.msaaFbo
+		f.dirty = true
+	}
+
 	mainthread.Call(func() {
 		// TODO - Note: I set the viewport when I bind the framebuffer. Is this okay?
 		gl.Viewport(0, 0, int(f.bounds.W()), int(f.bounds.H()))
-		gl.BindFramebuffer(gl.FRAMEBUFFER, f.fbo)
+		gl.BindFramebuffer(gl.FRAMEBUFFER, fbo)
 	})
 }
 
@@ -0,0 +1,85 @@
+package glitch
+
+// A convex volume used to cull things which are out of view. Each plane is stored as (a, b, c, d), where points with a*x + b*y + c*z + d >= 0 are inside
+type Frustum struct {
+	planes [6]Vec4
+}
+
+// Returns the frustum of a projection * view matrix. Everything which that matrix maps into clip space is inside
+func NewFrustum(viewProjection Mat4) *Frustum {
+	m := viewProjection
+	// The rows of the column major matrix
+	row := func(i int) Vec4 {
+		return Vec4{m[i], m[4+i], m[8+i], m[12+i]}
+	}
+	add := func(a, b Vec4) Vec4 { return Vec4{a[0] + b[0], a[1] + b[1], a[2] + b[2], a[3] + b[3]} }
+	sub := func(a, b Vec4) Vec4 { return Vec4{a[0] - b[0], a[1] - b[1], a[2] - b[2], a[3] - b[3]} }
+
+	r0, r1, r2, r3 := row(0), row(1), row(2), row(3)
+	return &Frustum{
+		planes: [6]Vec4{
+			add(r3, r0), // Left
+			sub(r3, r0), // Right
+			add(r3, r1), // Bottom
+			sub(r3, r1), // Top
+			add(r3, r2), // Near
+			sub(r3, r2), // Far
+		},
+	}
+}
+
+// Returns a frustum which contains everything inside of the rect along the x and y axes, with any z value. Useful for culling 2D scenes against a world space viewport
+func NewFrustumRect(rect Rect) *Frustum {
+	rect = rect.Norm()
+	return &Frustum{
+		planes: [6]Vec4{
+			Vec4{1, 0, 0, -rect.Min[0]},
+			Vec4{-1, 0, 0, rect.Max[0]},
+			Vec4{0, 1, 0, -rect.Min[1]},
+			Vec4{0, -1, 0, rect.Max[1]},
+			Vec4{0, 0, 0, 1}, // Always inside
+			Vec4{0, 0, 0, 1},
+		},
+	}
+}
+
+// Returns true if any part of the box might be inside of the frustum. Boxes near the corners of the frustum can be reported as inside even though they aren't, so this is only for culling
+func (f *Frustum) IntersectsBox(b Box) bool {
+	for _, p := range f.planes {
+		// Test the corner of the box that is furthest along the plane's normal
+		x, y, z := b.Min[0], b.Min[1], b.Min[2]
+		if p[0] >= 0 {
+			x = b.Max[0]
+		}
+		if p[1] >= 0 {
+			y = b.Max[1]
+		}
+		if p[2] >= 0 {
+			z = b.Max[2]
+		}
+		if (p[0] * x) + (p[1] * y) + (p[2] * z) + p[3] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns true if the point is inside of the frustum
+func (f *Frustum) Contains(point Vec3) bool {
+	return f.IntersectsBox(Box{point, point})
+}
+
+// Returns the frustum of everything the camera can see
+func (c *CameraOrtho) Frustum() *Frustum {
+	return NewFrustum(*c.Projection.Mul(&c.View))
+}
+
+// Returns the frustum of everything the camera can see
+func (c *Camera) Frustum() *Frustum {
+	return NewFrustum(*c.Projection.Mul(&c.View))
+}
+
+// Returns true if the mesh is completely outside of the frustum when it is drawn with the matrix
+func (f *Frustum) culls(mesh *Mesh, matrix Mat4) bool {
+	return !f.IntersectsBox(mesh.TransformedBounds(matrix))
+}
@@ -0,0 +1,78 @@
+package glitch
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestFrustumOrtho(t *testing.T) {
+	camera := NewCameraOrtho()
+	camera.SetOrtho2D(R(0, 0, 100, 100))
+	camera.SetView2D(50, 0, 1, 1) // Looks at x from 50 to 150
+	f := camera.Frustum()
+
+	if !f.IntersectsBox(R(60, 10, 70, 20).ToBox()) {
+		t.Fatalf("Box in view was culled")
+	}
+	if f.IntersectsBox(R(10, 10, 20, 20).ToBox()) {
+		t.Fatalf("Box out of view wasn't culled")
+	}
+	if !f.IntersectsBox(R(40, 10, 55, 20).ToBox()) {
+		t.Fatalf("Box on the edge of the view was culled")
+	}
+
+	r := NewFrustumRect(R(0, 0, 10, 10))
+	if !r.Contains(Vec3{5, 5, 1000}) || r.Contains(Vec3{11, 5, 0}) {
+		t.Fatalf("Rect frustum is wrong")
+	}
+}
+
+func TestFrustumPerspective(t *testing.T) {
+	camera := NewCamera()
+	camera.Projection = Mat4(mgl32.Perspective(math.Pi/4, 1, 0.1, 100))
+	camera.View = Mat4(mgl32.LookAt(0, 0, 0, 1, 0, 0, 0, 0, 1)) // Looking down +x
+	f := camera.Frustum()
+
+	unit := Box{Vec3{-1, -1, -1}, Vec3{1, 1, 1}}
+	mat := Mat4Ident
+	mat.Translate(10, 0, 0)
+	if !f.IntersectsBox(unit.Transform(mat)) {
+		t.Fatalf("Box in front of the camera was culled")
+	}
+	mat = Mat4Ident
+	mat.Translate(-10, 0, 0)
+	if f.IntersectsBox(unit.Transform(mat)) {
+		t.Fatalf("Box behind the camera wasn't culled")
+	}
+	mat = Mat4Ident
+	mat.Translate(200, 0, 0)
+	if f.IntersectsBox(unit.Transform(mat)) {
+		t.Fatalf("Box past the far plane wasn't culled")
+	}
+}
+
+func TestRenderPassCulling(t *testing.T) {
+	pass := &RenderPass{
+		commands: make([][]drawCommand, 256),
+		currentLayer: DefaultLayer,
+	}
+	pass.SetCulling(NewFrustumRect(R(0, 0, 100, 100)))
+
+	quad := NewQuadMesh(R(-1, -1, 1, 1), R(0, 0, 1, 1))
+	material := &SpriteMaterial{}
+	for x := float32(0); x < 1000; x += 10 {
+		mat := Mat4Ident
+		mat.Translate(x, 50, 0)
+		pass.Add(quad, mat, White, material)
+	}
+
+	stats := pass.Stats()
+	if stats.Commands != 11 || stats.Culled != 89 {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+	if len(pass.commands[DefaultLayer]) != stats.Commands {
+		t.Fatalf("Culled commands were added")
+	}
+}
@@ -7,21 +7,51 @@ import (
 
 type GeomDraw struct {
 	color RGBA
+	gradient *Gradient
 	Divisions int
+
+	// Line settings (See Stroke)
+	Join LineJoin
+	Cap LineCap
+	MiterLimit float32 // The longest a miter join can be, relative to the line width. 0 uses DefaultMiterLimit
+	Dashes []float32 // Alternating on and off lengths. Empty draws solid lines
+	DashOffset float32 // How far into the dash pattern lines start
+
+	// Anti aliases the edges of shapes by fading them out over this distance, past the edge of the shape. Set it to the size of a pixel in the units you draw with. 0 draws hard edges
+	Feather float32
 }
 
 func NewGeomDraw() *GeomDraw {
 	return &GeomDraw{
 		color: RGBA{1, 1, 1, 1},
 		Divisions: 100,
+		Join: JoinMiter,
+		Cap: CapButt,
+		MiterLimit: DefaultMiterLimit,
 	}
 }
 
+// Sets a solid color for the shapes drawn after this. Replaces any gradient
 func (g *GeomDraw) SetColor(color RGBA) {
 	g.color = color
+	g.gradient = nil
+}
+
+// Colors the shapes drawn after this with the gradient, through their vertex colors. Pass nil to go back to the solid color
+func (g *GeomDraw) SetGradient(gradient *Gradient) {
+	g.gradient = gradient
 }
 
 func (g *GeomDraw) FillRect(rect Rect) *Mesh {
+	if g.Feather > 0 || g.gradient != nil {
+		return g.FillPolygon([]Vec3{
+			Vec3{rect.Min[0], rect.Min[1], 0},
+			Vec3{rect.Max[0], rect.Min[1], 0},
+			Vec3{rect.Max[0], rect.Max[1], 0},
+			Vec3{rect.Min[0], rect.Max[1], 0},
+		})
+	}
+
 	positions := []Vec3{
 		Vec3{rect.Min[0], rect.Max[1], 0},
 		Vec3{rect.Min[0], rect.Min[1], 0},
@@ -52,6 +82,7 @@ func (g *GeomDraw) FillRect(rect Rect) *Mesh {
 		colors: colors,
 		texCoords: texCoords,
 		indices: inds,
+		bounds: positionBounds(positions),
 	}
 }
 
@@ -61,6 +92,25 @@ func (g *GeomDraw) Rectangle(rect Rect, width float32) *Mesh {
 		return g.FillRect(rect)
 	}
 
+	if g.Feather > 0 {
+		// Separate rects would fade out along the seams between them, so cut the inside out of a single polygon instead
+		inner := R(rect.Min[0] + width, rect.Min[1] + width, rect.Max[0] - width, rect.Max[1] - width)
+		return g.FillPolygon(
+			[]Vec3{
+				Vec3{rect.Min[0], rect.Min[1], 0},
+				Vec3{rect.Max[0], rect.Min[1], 0},
+				Vec3{rect.Max[0], rect.Max[1], 0},
+				Vec3{rect.Min[0], rect.Max[1], 0},
+			},
+			[]Vec3{
+				Vec3{inner.Min[0], inner.Min[1], 0},
+				Vec3{inner.Max[0], inner.Min[1], 0},
+				Vec3{inner.Max[0], inner.Max[1], 0},
+				Vec3{inner.Min[0], inner.Max[1], 0},
+			},
+		)
+	}
+
 	t := rect.CutTop(width)
 	b := rect.CutBottom(width)
 	l := rect.CutLeft(width)
@@ -75,112 +125,206 @@ func (g *GeomDraw) Rectangle(rect Rect, width float32) *Mesh {
 	return m
 }
 
+// The radius of each corner of a rounded rect. The top is the Max y side of the rect
+type CornerRadii struct {
+	TopLeft, TopRight, BottomRight, BottomLeft float32
+}
+
+// Returns radii which are the same for every corner
+func Radii(radius float32) CornerRadii {
+	return CornerRadii{radius, radius, radius, radius}
+}
+
+// Draws a rect with rounded corners. If width <= 0, then the rect is filled. Otherwise the outline is drawn inside of the rect, like Rectangle, and the inner corners are rounded by the radii minus the width.
+// Radii which are too big to fit are scaled down, like in CSS
+func (g *GeomDraw) RoundedRect(rect Rect, radii CornerRadii, width float32) *Mesh {
+	outline := roundedRectPoints(rect, radii, g.Divisions)
+	if width <= 0 {
+		return g.FillPolygon(outline)
+	}
+
+	inner := R(rect.Min[0] + width, rect.Min[1] + width, rect.Max[0] - width, rect.Max[1] - width)
+	if inner.W() <= 0 || inner.H() <= 0 {
+		return g.FillPolygon(outline)
+	}
+	innerRadii := CornerRadii{
+		float32(math.Max(0, float64(radii.TopLeft - width))),
+		float32(math.Max(0, float64(radii.TopRight - width))),
+		float32(math.Max(0, float64(radii.BottomRight - width))),
+		float32(math.Max(0, float64(radii.BottomLeft - width))),
+	}
+	return g.FillPolygon(outline, roundedRectPoints(inner, innerRadii, g.Divisions))
+}
+
+// Returns the outline of a rounded rect, counter clockwise from the bottom left corner
+func roundedRectPoints(rect Rect, radii CornerRadii, divisions int) []Vec3 {
+	rect = rect.Norm()
+	w := rect.W()
+	h := rect.H()
+
+	// Scale the radii down until adjacent corners fit along each side
+	scale := float32(1)
+	fit := func(length, r1, r2 float32) {
+		if r1 + r2 > 0 && length / (r1 + r2) < scale {
+			scale = length / (r1 + r2)
+		}
+	}
+	fit(w, radii.TopLeft, radii.TopRight)
+	fit(w, radii.BottomLeft, radii.BottomRight)
+	fit(h, radii.TopLeft, radii.BottomLeft)
+	fit(h, radii.TopRight, radii.BottomRight)
+
+	steps := divisions / 4
+	if steps < 1 {
+		steps = 1
+	}
+
+	corners := []struct{
+		radius float32
+		center Vec2
+		start float64
+	}{
+		{radii.BottomLeft * scale, Vec2{rect.Min[0], rect.Min[1]}, math.Pi},
+		{radii.BottomRight * scale, Vec2{rect.Max[0], rect.Min[1]}, 1.5 * math.Pi},
+		{radii.TopRight * scale, Vec2{rect.Max[0], rect.Max[1]}, 0},
+		{radii.TopLeft * scale, Vec2{rect.Min[0], rect.Max[1]}, 0.5 * math.Pi},
+	}
+
+	points := make([]Vec3, 0, 4 * (steps + 1))
+	for _, c := range corners {
+		if c.radius <= 0 {
+			points = append(points, c.center.Vec3())
+			continue
+		}
+
+		// Move the center in from the corner of the rect
+		center := c.center
+		if center[0] == rect.Min[0] {
+			center[0] += c.radius
+		} else {
+			center[0] -= c.radius
+		}
+		if center[1] == rect.Min[1] {
+			center[1] += c.radius
+		} else {
+			center[1] -= c.radius
+		}
+
+		for i := 0; i <= steps; i++ {
+			angle := c.start + (0.5 * math.Pi * float64(i) / float64(steps))
+			points = append(points, Vec3{
+				center[0] + c.radius * float32(math.Cos(angle)),
+				center[1] + c.radius * float32(math.Sin(angle)),
+				0,
+			})
+		}
+	}
+	return dedupPoints(points, true)
+}
+
 func (g *GeomDraw) Circle(center Vec3, radius float32, width float32) *Mesh {
 	return g.Ellipse(center, Vec2{radius, radius}, 0, width)
 }
 
+// if width <= 0, then fill the ellipse
 func (g *GeomDraw) Ellipse(center Vec3, size Vec2, rotation float32, width float32) *Mesh {
 	if width <= 0 {
-		panic("TODO - Fill Ellipse")
+		return g.FillEllipse(center, size, rotation)
 	}
 
-	alpha := float64(rotation)
+	points := EllipsePoints(size, rotation, g.Divisions)
+	for i := range points {
+		points[i] = center.Add(points[i])
+	}
 
-	a := math.Max(float64(size[0]), float64(size[1])) // SemiMajorAxis
-	b := math.Min(float64(size[0]), float64(size[1])) // SemiMinorAxis
-	// TODO - Rotate pi/2 if width < height?
-	e := math.Sqrt(1 - (b*b)/(a*a)) // Eccintricity
+	return g.Stroke(points, width, true)
+}
 
-	points := make([]Vec3, g.Divisions, g.Divisions)
-	radians := 0.0
-	for i := range points {
-		eCos := (e * math.Cos(radians))
-		r := b / (math.Sqrt(1 - (eCos * eCos)))
+// Fills the ellipse with a triangle fan around the center
+func (g *GeomDraw) FillEllipse(center Vec3, size Vec2, rotation float32) *Mesh {
+	points := EllipsePoints(size, rotation, g.Divisions)
 
-		points[i] = center.Add(Vec3{
-		float32(r * math.Cos(radians - alpha)),
-		float32(r * math.Sin(radians - alpha)),
-		0,
-		})
-		radians += 2 * math.Pi / float64(g.Divisions)
-	}
-	// Append last point
-	{
-		eCos := (e * math.Cos(radians))
-		r := b / (math.Sqrt(1 - (eCos * eCos)))
-		// r := a * (1 - e * e) / (1 + (e * math.Cos(radians - alpha)))
-		// r := l / (1 + (e * math.Cos(radians - alpha)))
-		lastPoint := center.Add(Vec3{
-		float32(r * math.Cos(radians - alpha)),
-		float32(r * math.Sin(radians - alpha)),
-		0,
-		})
-		points = append(points, lastPoint)
+	positions := make([]Vec3, 0, len(points) + 1)
+	positions = append(positions, center)
+	for i := range points {
+		positions = append(positions, center.Add(points[i]))
 	}
 
-	// // Circle only
-	// points := make([]Vec3, g.Divisions, g.Divisions)
-	// radians := 0.0
-	// for i := range points {
-	// 	points[i] = center.Add(Vec3{
-	// 		radius * float32(math.Cos(radians)),
-	// 		(22.0/32.0) * radius * float32(math.Sin(radians)),
-	// 		0,
-	// 	})
-	// 	radians += 2 * math.Pi / float64(g.Divisions)
-	// }
-	// // Append last point
-	// points = append(points, center.Add(Vec3{radius, 0, 0}))
+	inds := make([]uint32, 0, 3 * len(points))
+	for i := range points {
+		next := (i + 1) % len(points)
+		inds = append(inds, 0, uint32(i + 1), uint32(next + 1))
+	}
 
-	return g.LineStrip(points, width)
+	return g.fillMesh(positions, inds)
 }
 
 func (g *GeomDraw) LineStrip(points []Vec3, width float32) *Mesh {
-	// fmt.Println("Points:", points)
-	m := NewMesh()
-	c := points[0]
-	for i := 0; i < len(points); i++ {
-		b := points[i]
-		if i+1 < len(points) {
-			c = points[i+1]
-		}
+	return g.Stroke(points, width, false)
+}
 
-		// Note: Divide by 2 because each connection spills over have the midpoint of the joint
-		m.Append(g.Line(b, c, 0, 0, width))
+// if width <= 0, then fill the polygon
+func (g *GeomDraw) Polygon(points []Vec3, width float32) *Mesh {
+	if width <= 0 {
+		return g.FillPolygon(points)
 	}
+	return g.Stroke(points, width, true)
+}
 
-	return m
+// Fills a simple polygon, which can be concave. Holes are cut out of the polygon, and must be inside of it without touching each other (See Triangulate)
+func (g *GeomDraw) FillPolygon(points []Vec3, holes ...[]Vec3) *Mesh {
+	positions := make([]Vec3, 0, len(points))
+	positions = append(positions, points...)
+	for _, hole := range holes {
+		if len(hole) < 3 { continue } // Skipped by Triangulate too, so the indices still line up
+		positions = append(positions, hole...)
+	}
+
+	return g.fillMesh(positions, Triangulate(points, holes...))
 }
 
-// TODO - remake linestrip but don't have the looping indexes (ie modulo). This is technically for polygons
-func (g *GeomDraw) Polygon(points []Vec3, width float32) *Mesh {
-	// fmt.Println("Points:", points)
-	m := NewMesh()
-	// for i := 0; i < len(points)-1; i++ {
-	a := points[len(points)-1]
-	for i := 0; i < len(points); i++ {
-		if i > 0 {
-			a = points[i-1]
-		}
-		b := points[i]
-		c := points[(i+1) % len(points)]
-		d := points[(i+2) % len(points)]
+// Builds a mesh of the current color or gradient, feathering its edges if enabled. Texture coordinates stretch over the bounds of the shape
+func (g *GeomDraw) fillMesh(positions []Vec3, inds []uint32) *Mesh {
+	if g.gradient != nil {
+		positions, inds = g.gradient.tessellate(positions, inds)
+	}
+
+	faded := len(positions)
+	if g.Feather > 0 {
+		positions, inds, faded = featherMesh(positions, inds, g.Feather)
+	}
 
-		v0 := b.Sub(a)
-		v1 := c.Sub(b)
-		v2 := d.Sub(c)
-		// fmt.Println("Index:", i, v0, v1, v2)
-		// Note: Divide by 2 because each connection spills over have the midpoint of the joint
-		m.Append(g.Line(b, c, v0.Angle(v1) / 2, v1.Angle(v2) / 2, width))
-		// m.Append(g.Line(points[i], points[i+1], v0.Angle(v1), v1.Angle(v2), width))
+	bounds := positionBounds(positions)
+	w := bounds.Max[0] - bounds.Min[0]
+	h := bounds.Max[1] - bounds.Min[1]
+
+	colors := make([]Vec4, len(positions))
+	texCoords := make([]Vec2, len(positions))
+	for i, p := range positions {
+		if i < faded {
+			color := g.color
+			if g.gradient != nil {
+				color = g.gradient.At(p.Vec2())
+			}
+			colors[i] = Vec4{color.R, color.G, color.B, color.A}
+		} else {
+			colors[i] = Vec4{0, 0, 0, 0} // Colors are premultiplied, so this fades to transparent
+		}
+		if w > 0 && h > 0 {
+			texCoords[i] = Vec2{(p[0] - bounds.Min[0]) / w, (bounds.Max[1] - p[1]) / h}
+		}
+	}
 
-		// m.Append(g.Line(points[i], points[i+1], width))
+	return &Mesh{
+		positions: positions,
+		colors: colors,
+		texCoords: texCoords,
+		indices: inds,
+		bounds: bounds,
 	}
-	// fmt.Println("Positions:" m.positions)
-	return m
 }
 
-// TODO different line endings
+// Draws a single segment with its ends cut at the given angles. Use Stroke for connected lines with joins and caps
 func (g *GeomDraw) Line(a, b Vec3, lastAngle, nextAngle float32, width float32) *Mesh {
 	// fmt.Println("Angles:", lastAngle, nextAngle)
 
@@ -265,6 +409,7 @@ func (g *GeomDraw) Line(a, b Vec3, lastAngle, nextAngle float32, width float32)
 		colors: colors,
 		texCoords: texCoords,
 		indices: inds,
+		bounds: positionBounds(positions),
 	}
 }
 
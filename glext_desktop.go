@@ -0,0 +1,105 @@
+//go:build !js
+
+package glitch
+
+import (
+	"github.com/unitoftime/gl"
+
+	glcore "github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// These are GL calls which the gl package either doesn't wrap or doesn't support on every platform
+// Note: Must be called on the mainthread
+
+// Desktop GL can swizzle texture channels, so FormatR8 textures are stored as a single channel
+const textureSwizzleSupported = true
+
+// Returns true if multisampled renderbuffers, multiple draw buffers and framebuffer blits are available. These are core in GL 3.3
+func framebufferExtSupported() bool {
+	return true
+}
+
+// Desktop GL extensions don't need to be enabled, so callers still need to check for errors when they use them
+func enableExtension(name string) bool {
+	return true
+}
+
+// Returns a single integer parameter
+func getIntParameter(pname gl.Enum) int {
+	val := []int32{0}
+	gl.GetIntegerv(pname, val)
+	return int(val[0])
+}
+
+// Returns a single float parameter
+func getFloatParameter(pname gl.Enum) float32 {
+	val := []float32{0}
+	gl.GetFloatv(val, pname)
+	return val[0]
+}
+
+// Returns the compressed texture formats that the driver supports
+func compressedTextureFormats() []gl.Enum {
+	count := getIntParameter(gl.NUM_COMPRESSED_TEXTURE_FORMATS)
+	if count <= 0 { return nil }
+
+	vals := make([]int32, count)
+	gl.GetIntegerv(gl.COMPRESSED_TEXTURE_FORMATS, vals)
+	formats := make([]gl.Enum, count)
+	for i := range vals {
+		formats[i] = gl.Enum(vals[i])
+	}
+	return formats
+}
+
+// Allocates the bound texture's storage and uploads the pixels to it. If pixels is nil, the storage is allocated without uploading anything
+func texImage2D(internalFormat gl.Enum, width, height int, format, ty gl.Enum, pixels []byte) {
+	gl.TexImage2DFull(gl.TEXTURE_2D, 0, internalFormat, width, height, format, ty, pixels)
+}
+
+// Reads pixels from the bound framebuffer into dst. For gl.FLOAT, dst holds the bytes of the float32s (See floatBytes)
+func readPixels(dst []byte, x, y, width, height int, format, ty gl.Enum) {
+	gl.ReadPixels(dst, x, y, width, height, format, ty)
+}
+
+// Uploads the pixels to a section of the bound texture
+func texSubImage2D(x, y, width, height int, format, ty gl.Enum, pixels []byte) {
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, x, y, width, height, format, ty, pixels)
+}
+
+func renderbufferStorage(internalFormat gl.Enum, width, height int) {
+	gl.RenderbufferStorage(gl.RENDERBUFFER, internalFormat, width, height)
+}
+
+func framebufferRenderbuffer(attachment gl.Enum, rb gl.Renderbuffer) {
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, attachment, gl.RENDERBUFFER, rb)
+}
+
+func compressedTexImage2D(level int, internalFormat gl.Enum, width, height int, data []byte) {
+	gl.CompressedTexImage2D(gl.TEXTURE_2D, level, internalFormat, width, height, 0, data)
+}
+
+func stencilFunc(fn gl.Enum, ref int, mask uint32) {
+	gl.StencilFunc(fn, ref, mask)
+}
+
+func stencilOp(fail, zfail, zpass gl.Enum) {
+	gl.StencilOp(fail, zfail, zpass)
+}
+
+func renderbufferStorageMultisample(samples int, internalFormat gl.Enum, width, height int) {
+	glcore.RenderbufferStorageMultisample(gl.RENDERBUFFER, int32(samples), uint32(internalFormat), int32(width), int32(height))
+}
+
+func drawBuffers(buffers []gl.Enum) {
+	if len(buffers) <= 0 { return }
+	bufs := make([]uint32, len(buffers))
+	for i := range buffers {
+		bufs[i] = uint32(buffers[i])
+	}
+	glcore.DrawBuffers(int32(len(bufs)), &bufs[0])
+}
+
+func blitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int, mask, filter gl.Enum) {
+	gl.BlitFramebuffer(int32(srcX0), int32(srcY0), int32(srcX1), int32(srcY1), int32(dstX0), int32(dstY0), int32(dstX1), int32(dstY1), uint32(mask), uint32(filter))
+}
@@ -0,0 +1,155 @@
+//go:build js
+
+package glitch
+
+import (
+	"syscall/js"
+	"unsafe"
+
+	"github.com/unitoftime/gl"
+)
+
+// These are GL calls which the gl package either doesn't wrap or doesn't support on every platform
+// Note: Must be called on the mainthread
+
+// TODO - The gl package doesn't export its context, so we look it up from the canvas. Calling getContext again returns the context that was already created,
+// but asking for a different version than the canvas was created with returns null, so webgl1 is tried after webgl2
+var webglContext js.Value
+var webgl2 bool
+func getWebglContext() js.Value {
+	if webglContext.IsUndefined() {
+		canvas := js.Global().Get("document").Call("querySelector", "canvas")
+		webglContext = canvas.Call("getContext", "webgl2")
+		webgl2 = !webglContext.IsNull()
+		if !webgl2 {
+			webglContext = canvas.Call("getContext", "webgl")
+		}
+	}
+	return webglContext
+}
+
+// Returns true if multisampled renderbuffers, multiple draw buffers and framebuffer blits are available, which needs WebGL2.
+// The functions below must only be called when this is true
+func framebufferExtSupported() bool {
+	getWebglContext()
+	return webgl2
+}
+
+// WebGL can't swizzle texture channels, so FormatR8 textures are expanded to RGBA when they are uploaded
+const textureSwizzleSupported = false
+
+// WebGL extensions have to be enabled before their enums can be used. Returns false if the extension isn't supported
+func enableExtension(name string) bool {
+	ctx := getWebglContext()
+	if ctx.IsNull() || ctx.IsUndefined() {
+		return false
+	}
+	ext := ctx.Call("getExtension", name)
+	return !ext.IsNull() && !ext.IsUndefined()
+}
+
+// Returns a single integer parameter, or 0 if it isn't available. Like getFloatParameter, this reads the number that getParameter returns instead of an array
+func getIntParameter(pname gl.Enum) int {
+	val := getWebglContext().Call("getParameter", int(pname))
+	if val.Type() != js.TypeNumber {
+		return 0
+	}
+	return val.Int()
+}
+
+// Returns a single float parameter, or 0 if it isn't available. The gl package's GetFloatv expects getParameter to return an array, but scalar parameters come back as a number
+func getFloatParameter(pname gl.Enum) float32 {
+	val := getWebglContext().Call("getParameter", int(pname))
+	if val.Type() != js.TypeNumber {
+		return 0
+	}
+	return float32(val.Float())
+}
+
+// Returns the compressed texture formats that the enabled extensions provide.
+// WebGL doesn't have NUM_COMPRESSED_TEXTURE_FORMATS, getParameter returns the formats as a Uint32Array instead
+func compressedTextureFormats() []gl.Enum {
+	val := getWebglContext().Call("getParameter", gl.COMPRESSED_TEXTURE_FORMATS)
+	if val.IsNull() || val.IsUndefined() {
+		return nil
+	}
+	formats := make([]gl.Enum, val.Length())
+	for i := range formats {
+		formats[i] = gl.Enum(val.Index(i).Int())
+	}
+	return formats
+}
+
+// Allocates the bound texture's storage and uploads the pixels to it. If pixels is nil, the storage is allocated without uploading anything
+func texImage2D(internalFormat gl.Enum, width, height int, format, ty gl.Enum, pixels []byte) {
+	gl.TexImage2DFull(gl.TEXTURE_2D, 0, internalFormat, width, height, format, ty, texImageData(pixels, ty))
+}
+
+// Uploads the pixels to a section of the bound texture
+// Note: The gl package's TexSubImage2D passes the format to javascript as a gl.Enum, which syscall/js can't convert
+func texSubImage2D(x, y, width, height int, format, ty gl.Enum, pixels []byte) {
+	getWebglContext().Call("texSubImage2D", int(gl.TEXTURE_2D), 0, x, y, width, height, int(format), int(ty), gl.SliceToTypedArray(texImageData(pixels, ty)))
+}
+
+// Returns the value passed to the gl package's texture uploads.
+// A nil []byte is still a typed value once it is in an interface, so the gl package would turn it into an empty array (which webgl rejects) instead of null.
+// Webgl also requires a Float32Array for gl.FLOAT uploads, so float pixels (See floatBytes) are passed as a []float32
+func texImageData(pixels []byte, ty gl.Enum) interface{} {
+	if pixels == nil {
+		return nil
+	}
+	if ty == gl.FLOAT && len(pixels) >= 4 {
+		return unsafe.Slice((*float32)(unsafe.Pointer(&pixels[0])), len(pixels) / 4)
+	}
+	return pixels
+}
+
+// Reads pixels from the bound framebuffer into dst. For gl.FLOAT, dst holds the bytes of the float32s (See floatBytes)
+// The gl package's ReadPixels passes the Go slice straight to javascript, so this reads into a typed array and copies it back
+func readPixels(dst []byte, x, y, width, height int, format, ty gl.Enum) {
+	bytes := js.Global().Get("Uint8Array").New(len(dst))
+	buf := bytes
+	if ty == gl.FLOAT {
+		buf = js.Global().Get("Float32Array").New(bytes.Get("buffer"))
+	}
+	getWebglContext().Call("readPixels", x, y, width, height, int(format), int(ty), buf)
+	js.CopyBytesToGo(dst, bytes)
+}
+
+// The gl package passes the enums of these calls straight to javascript, and syscall/js panics on the gl.Enum type, so they are converted to ints here
+
+func renderbufferStorage(internalFormat gl.Enum, width, height int) {
+	getWebglContext().Call("renderbufferStorage", int(gl.RENDERBUFFER), int(internalFormat), width, height)
+}
+
+func framebufferRenderbuffer(attachment gl.Enum, rb gl.Renderbuffer) {
+	getWebglContext().Call("framebufferRenderbuffer", int(gl.FRAMEBUFFER), int(attachment), int(gl.RENDERBUFFER), rb.Value)
+}
+
+func compressedTexImage2D(level int, internalFormat gl.Enum, width, height int, data []byte) {
+	getWebglContext().Call("compressedTexImage2D", int(gl.TEXTURE_2D), level, int(internalFormat), width, height, 0, gl.SliceToTypedArray(data))
+}
+
+func stencilFunc(fn gl.Enum, ref int, mask uint32) {
+	getWebglContext().Call("stencilFunc", int(fn), ref, mask)
+}
+
+func stencilOp(fail, zfail, zpass gl.Enum) {
+	getWebglContext().Call("stencilOp", int(fail), int(zfail), int(zpass))
+}
+
+func renderbufferStorageMultisample(samples int, internalFormat gl.Enum, width, height int) {
+	getWebglContext().Call("renderbufferStorageMultisample", int(gl.RENDERBUFFER), samples, int(internalFormat), width, height)
+}
+
+func drawBuffers(buffers []gl.Enum) {
+	bufs := make([]interface{}, len(buffers))
+	for i := range buffers {
+		bufs[i] = int(buffers[i])
+	}
+	getWebglContext().Call("drawBuffers", bufs)
+}
+
+func blitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int, mask, filter gl.Enum) {
+	getWebglContext().Call("blitFramebuffer", srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1, int(mask), int(filter))
+}
@@ -0,0 +1,143 @@
+//go:build js
+
+package glitch
+
+import (
+	"syscall/js"
+	"testing"
+
+	"github.com/unitoftime/gl"
+)
+
+type fakeGLCall struct {
+	name string
+	args []js.Value
+}
+
+// Replaces the webgl context with a proxy that records every call and returns results[name], so the wrappers in glext_js.go can run without a browser.
+// Note: syscall/js panics before the call reaches javascript if a wrapper passes a value it can't convert (like a gl.Enum)
+func useFakeWebglContext(t *testing.T, results map[string]interface{}) *[]fakeGLCall {
+	calls := &[]fakeGLCall{}
+	handler := js.Global().Get("Object").New()
+	handler.Set("get", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		name := args[1].String()
+		return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			*calls = append(*calls, fakeGLCall{name, append([]js.Value(nil), args...)})
+			return results[name]
+		})
+	}))
+
+	prevContext, prevWebgl2 := webglContext, webgl2
+	webglContext = js.Global().Get("Proxy").New(js.Global().Get("Object").New(), handler)
+	webgl2 = true
+	t.Cleanup(func() {
+		webglContext, webgl2 = prevContext, prevWebgl2
+	})
+	return calls
+}
+
+// Fails unless exactly one call was recorded and all of its arguments are numbers (except the ones at the skipped indices)
+func checkFakeGLCall(t *testing.T, calls *[]fakeGLCall, name string, skip ...int) {
+	t.Helper()
+	if len(*calls) != 1 || (*calls)[0].name != name {
+		t.Fatalf("expected a single %s call, got %v", name, *calls)
+	}
+	for i, arg := range (*calls)[0].args {
+		skipped := false
+		for _, s := range skip {
+			skipped = skipped || s == i
+		}
+		if !skipped && arg.Type() != js.TypeNumber {
+			t.Fatalf("%s: argument %d should be a number, got %s", name, i, arg.Type())
+		}
+	}
+	*calls = (*calls)[:0]
+}
+
+func TestRenderbufferWrappers(t *testing.T) {
+	calls := useFakeWebglContext(t, nil)
+
+	renderbufferStorage(glDEPTH24_STENCIL8, 64, 32)
+	checkFakeGLCall(t, calls, "renderbufferStorage")
+
+	framebufferRenderbuffer(glDEPTH_STENCIL_ATTACHMENT, gl.Renderbuffer{Value: js.Global().Get("Object").New()})
+	checkFakeGLCall(t, calls, "framebufferRenderbuffer", 3)
+}
+
+func TestTexImageDataNil(t *testing.T) {
+	// Frames allocate their textures with nil pixels, which must reach webgl as null rather than an empty array
+	if data := texImageData(nil, gl.UNSIGNED_BYTE); data != nil {
+		t.Fatalf("expected untyped nil, got %T", data)
+	}
+	if data := texImageData(nil, gl.FLOAT); data != nil {
+		t.Fatalf("expected untyped nil, got %T", data)
+	}
+
+	pixels := []byte{1, 2, 3, 4}
+	data, ok := texImageData(pixels, gl.UNSIGNED_BYTE).([]byte)
+	if !ok || len(data) != len(pixels) {
+		t.Fatalf("expected the pixels to be passed through, got %v", data)
+	}
+}
+
+func TestTexImageDataFloat(t *testing.T) {
+	// Webgl only accepts a Float32Array for float uploads, which the gl package builds from a []float32
+	floats := []float32{0, 0.25, 0.5, 1}
+	data, ok := texImageData(floatBytes(floats), gl.FLOAT).([]float32)
+	if !ok || len(data) != len(floats) {
+		t.Fatalf("expected a []float32, got %T", data)
+	}
+	for i := range floats {
+		if data[i] != floats[i] {
+			t.Fatalf("wrong float %d: %v", i, data[i])
+		}
+	}
+}
+
+func TestGetIntParameter(t *testing.T) {
+	// Scalar parameters come back as a number, which the gl package's GetIntegerv can't read
+	calls := useFakeWebglContext(t, map[string]interface{}{"getParameter": 8})
+	if max := getIntParameter(glMAX_DRAW_BUFFERS); max != 8 {
+		t.Fatalf("expected 8, got %d", max)
+	}
+	checkFakeGLCall(t, calls, "getParameter")
+
+	// Unknown parameters return null
+	calls = useFakeWebglContext(t, nil)
+	if max := getIntParameter(glMAX_DRAW_BUFFERS); max != 0 {
+		t.Fatalf("expected 0, got %d", max)
+	}
+}
+
+func TestStencilWrappers(t *testing.T) {
+	calls := useFakeWebglContext(t, nil)
+
+	stencilFunc(gl.ALWAYS, 1, 0xFF)
+	checkFakeGLCall(t, calls, "stencilFunc")
+
+	stencilOp(gl.KEEP, gl.KEEP, gl.REPLACE)
+	checkFakeGLCall(t, calls, "stencilOp")
+}
+
+func TestCompressedTextureWrappers(t *testing.T) {
+	// WebGL returns the formats as a typed array, and has no NUM_COMPRESSED_TEXTURE_FORMATS to query first
+	formats := js.Global().Get("Uint32Array").New(2)
+	formats.SetIndex(0, glCOMPRESSED_RGBA_S3TC_DXT1)
+	formats.SetIndex(1, glCOMPRESSED_RGB8_ETC2)
+	calls := useFakeWebglContext(t, map[string]interface{}{"getParameter": formats})
+
+	got := compressedTextureFormats()
+	if len(got) != 2 || got[0] != glCOMPRESSED_RGBA_S3TC_DXT1 || got[1] != glCOMPRESSED_RGB8_ETC2 {
+		t.Fatalf("wrong formats: %v", got)
+	}
+	checkFakeGLCall(t, calls, "getParameter")
+
+	compressedTexImage2D(0, glCOMPRESSED_RGBA_S3TC_DXT1, 4, 4, make([]byte, 8))
+	checkFakeGLCall(t, calls, "compressedTexImage2D", 6)
+
+	// Without any compressed texture extensions, getParameter returns null
+	useFakeWebglContext(t, nil)
+	if got := compressedTextureFormats(); len(got) != 0 {
+		t.Fatalf("expected no formats, got %v", got)
+	}
+}
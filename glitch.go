@@ -1,6 +1,8 @@
 package glitch
 
 import (
+	"fmt"
+
 	"github.com/unitoftime/gl"
 	"github.com/faiface/mainthread"
 
@@ -94,11 +96,52 @@ func Clear(target Target, color RGBA) {
 	mainthread.Call(func() {
 		gl.ClearColor(color.R, color.G, color.B, color.A)
 		// gl.Clear(gl.COLOR_BUFFER_BIT)
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT | gl.STENCIL_BUFFER_BIT)
 // TODO - depth buffer bit?		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 	})
 }
 
+// Copies the srcRect region of src into the dstRect region of dst, scaling it with the filter if the sizes differ. Only the color buffer is copied.
+// Targets must be a *Frame or a *Window. Multisampled frames are resolved before being read, and are written through their resolved textures.
+// Returns an error without copying anything if framebuffer blits aren't supported (they need WebGL2 on the web). In that case, draw the frame with Frame.Draw or a PostEffect instead.
+// Note: Rects are in pixels, with the origin at the bottom left of the target
+func Blit(src, dst Target, srcRect, dstRect Rect, filter TextureFilter) error {
+	supported := false
+	mainthread.Call(func() {
+		supported = framebufferExtSupported()
+	})
+	if !supported {
+		return fmt.Errorf("Blit: framebuffer blits aren't supported by the driver")
+	}
+
+	srcFbo := blitFramebufferOf(src)
+	dstFbo := blitFramebufferOf(dst)
+
+	mainthread.Call(func() {
+		prevFbo := gl.GetBoundFramebuffer()
+		gl.BindFramebuffer(gl.READ_FRAMEBUFFER, srcFbo)
+		gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, dstFbo)
+		blitFramebuffer(
+			int(srcRect.Min[0]), int(srcRect.Min[1]), int(srcRect.Max[0]), int(srcRect.Max[1]),
+			int(dstRect.Min[0]), int(dstRect.Min[1]), int(dstRect.Max[0]), int(dstRect.Max[1]),
+			gl.COLOR_BUFFER_BIT, gl.Enum(filter.glFilter()))
+		gl.BindFramebuffer(gl.FRAMEBUFFER, prevFbo)
+	})
+	return nil
+}
+
+func blitFramebufferOf(target Target) gl.Framebuffer {
+	switch t := target.(type) {
+	case *Window:
+		return gl.NoFramebuffer
+	case *Frame:
+		t.syncWindowSize()
+		t.resolveIfDirty() // Also keeps a pending resolve from overwriting what gets blitted into the frame
+		return t.fbo
+	}
+	panic(fmt.Sprintf("Blit: unsupported target type %T", target))
+}
+
 /*
 func FinalizeDraw() {
 	context.vertexBuffer.Bind()
@@ -0,0 +1,244 @@
+package glitch
+
+import (
+	"math"
+	"sort"
+)
+
+// A color at a point along a gradient
+type GradientStop struct {
+	Offset float32 // 0 is the start of the gradient and 1 is the end
+	Color RGBA
+}
+
+// A linear or radial color gradient, used to color GeomDraw shapes (See GeomDraw.SetGradient).
+// Points before the first stop or after the last stop take the color of that stop
+type Gradient struct {
+	radial bool
+	start, end Vec2 // For radial gradients, start is the center
+	radius float32
+	stops []GradientStop
+}
+
+// Blends from start (offset 0) to end (offset 1), with the color constant along lines perpendicular to the gradient
+func NewLinearGradient(start, end Vec2, stops ...GradientStop) *Gradient {
+	return newGradient(&Gradient{
+		start: start,
+		end: end,
+	}, stops)
+}
+
+// Blends from the center (offset 0) out to the radius (offset 1)
+func NewRadialGradient(center Vec2, radius float32, stops ...GradientStop) *Gradient {
+	return newGradient(&Gradient{
+		radial: true,
+		start: center,
+		radius: radius,
+	}, stops)
+}
+
+func newGradient(g *Gradient, stops []GradientStop) *Gradient {
+	g.stops = append([]GradientStop{}, stops...)
+	sort.SliceStable(g.stops, func(i, j int) bool {
+		return g.stops[i].Offset < g.stops[j].Offset
+	})
+	return g
+}
+
+// Returns how far along the gradient the point is
+func (g *Gradient) offset(p Vec2) float32 {
+	if g.radial {
+		if g.radius <= 0 { return 1 }
+		return p.Sub(g.start).Len() / g.radius
+	}
+
+	dir := g.end.Sub(g.start)
+	lenSq := (dir[0] * dir[0]) + (dir[1] * dir[1])
+	if lenSq == 0 { return 0 }
+	d := p.Sub(g.start)
+	return ((d[0] * dir[0]) + (d[1] * dir[1])) / lenSq
+}
+
+// Returns the color of the gradient at the point
+func (g *Gradient) At(p Vec2) RGBA {
+	return g.colorAt(g.offset(p))
+}
+
+func (g *Gradient) colorAt(t float32) RGBA {
+	if len(g.stops) == 0 {
+		return RGBA{0, 0, 0, 0}
+	}
+	if t <= g.stops[0].Offset {
+		return g.stops[0].Color
+	}
+	for i := 1; i < len(g.stops); i++ {
+		a := g.stops[i-1]
+		b := g.stops[i]
+		if t > b.Offset { continue }
+
+		f := (t - a.Offset) / (b.Offset - a.Offset)
+		return RGBA{
+			a.Color.R + (b.Color.R - a.Color.R) * f,
+			a.Color.G + (b.Color.G - a.Color.G) * f,
+			a.Color.B + (b.Color.B - a.Color.B) * f,
+			a.Color.A + (b.Color.A - a.Color.A) * f,
+		}
+	}
+	return g.stops[len(g.stops)-1].Color
+}
+
+// Splits up the triangles so that colors interpolated between their vertices follow the gradient
+func (g *Gradient) tessellate(positions []Vec3, inds []uint32) ([]Vec3, []uint32) {
+	if len(g.stops) == 0 {
+		return positions, inds
+	}
+	if g.radial {
+		return g.subdivide(positions, inds)
+	}
+	return g.splitBands(positions, inds)
+}
+
+type gradientCut struct {
+	a, b uint32 // The edge being cut, with a < b
+	cut int
+}
+
+// Linear gradients blend linearly between stops, so they only need to be cut along the line of each stop
+func (g *Gradient) splitBands(positions []Vec3, inds []uint32) ([]Vec3, []uint32) {
+	cuts := make([]float32, 0, len(g.stops))
+	for _, s := range g.stops {
+		if len(cuts) > 0 && cuts[len(cuts)-1] == s.Offset { continue }
+		cuts = append(cuts, s.Offset)
+	}
+
+	positions = append([]Vec3{}, positions...)
+	offsets := make([]float32, len(positions))
+	for i := range positions {
+		offsets[i] = g.offset(positions[i].Vec2())
+	}
+
+	// New vertices are shared by both triangles on an edge, so the mesh stays watertight
+	cache := make(map[gradientCut]uint32)
+	cutVertex := func(a, b uint32, cut int) uint32 {
+		if a > b {
+			a, b = b, a
+		}
+		key := gradientCut{a, b, cut}
+		if idx, ok := cache[key]; ok {
+			return idx
+		}
+		f := (cuts[cut] - offsets[a]) / (offsets[b] - offsets[a])
+		d := positions[b].Sub(positions[a])
+		positions = append(positions, positions[a].Add(d.Scaled(f, f, f)))
+		offsets = append(offsets, cuts[cut])
+		idx := uint32(len(positions) - 1)
+		cache[key] = idx
+		return idx
+	}
+
+	// Clips the polygon to one side of a cut
+	clip := func(poly []uint32, cut int, above bool) []uint32 {
+		c := cuts[cut]
+		ret := make([]uint32, 0, len(poly) + 1)
+		for i := range poly {
+			a := poly[i]
+			b := poly[(i + 1) % len(poly)]
+			if (above && offsets[a] >= c) || (!above && offsets[a] <= c) {
+				ret = append(ret, a)
+			}
+			if (offsets[a] - c) * (offsets[b] - c) < 0 {
+				ret = append(ret, cutVertex(a, b, cut))
+			}
+		}
+		return ret
+	}
+
+	ret := make([]uint32, 0, len(inds))
+	for i := 0; i + 2 < len(inds); i += 3 {
+		tri := []uint32{inds[i], inds[i+1], inds[i+2]}
+		for band := 0; band <= len(cuts); band++ {
+			piece := tri
+			if band > 0 {
+				piece = clip(piece, band - 1, true)
+			}
+			if band < len(cuts) && len(piece) >= 3 {
+				piece = clip(piece, band, false)
+			}
+			if len(piece) < 3 { continue }
+
+			// Pieces lying exactly on a cut line belong to the band below it
+			center := float32(0)
+			for _, v := range piece {
+				center += offsets[v]
+			}
+			center /= float32(len(piece))
+			if band > 0 && center <= cuts[band-1] { continue }
+
+			for j := 1; j + 1 < len(piece); j++ {
+				ret = append(ret, piece[0], piece[j], piece[j+1])
+			}
+		}
+	}
+	return positions, ret
+}
+
+// The most times that radial gradients subdivide a mesh. Each level makes 4 times as many triangles
+const maxGradientSubdivisions = 4
+
+// Radial gradients don't blend linearly across a triangle, so the mesh is evenly subdivided until its edges are short relative to the radius
+func (g *Gradient) subdivide(positions []Vec3, inds []uint32) ([]Vec3, []uint32) {
+	maxEdge := float32(0)
+	for i := 0; i + 2 < len(inds); i += 3 {
+		for j := 0; j < 3; j++ {
+			a := positions[inds[i + j]]
+			b := positions[inds[i + (j + 1) % 3]]
+			if l := b.Sub(a).Len(); l > maxEdge {
+				maxEdge = l
+			}
+		}
+	}
+
+	target := g.radius / 8
+	if target <= 0 || maxEdge <= target {
+		return positions, inds
+	}
+	levels := int(math.Ceil(math.Log2(float64(maxEdge / target))))
+	if levels > maxGradientSubdivisions {
+		levels = maxGradientSubdivisions
+	}
+
+	positions = append([]Vec3{}, positions...)
+	for l := 0; l < levels; l++ {
+		cache := make(map[[2]uint32]uint32)
+		midpoint := func(a, b uint32) uint32 {
+			if a > b {
+				a, b = b, a
+			}
+			key := [2]uint32{a, b}
+			if idx, ok := cache[key]; ok {
+				return idx
+			}
+			d := positions[b].Sub(positions[a])
+			positions = append(positions, positions[a].Add(d.Scaled(0.5, 0.5, 0.5)))
+			idx := uint32(len(positions) - 1)
+			cache[key] = idx
+			return idx
+		}
+
+		next := make([]uint32, 0, 4 * len(inds))
+		for i := 0; i + 2 < len(inds); i += 3 {
+			a, b, c := inds[i], inds[i+1], inds[i+2]
+			ab := midpoint(a, b)
+			bc := midpoint(b, c)
+			ca := midpoint(c, a)
+			next = append(next,
+				a, ab, ca,
+				ab, b, bc,
+				ca, bc, c,
+				ab, bc, ca,
+			)
+		}
+		inds = next
+	}
+	return positions, inds
+}
@@ -0,0 +1,70 @@
+package glitch
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGradientAt(t *testing.T) {
+	g := NewLinearGradient(Vec2{0, 0}, Vec2{10, 0},
+		GradientStop{1, RGBA{0, 0, 1, 1}},
+		GradientStop{0, RGBA{1, 0, 0, 1}},
+	)
+	if c := g.At(Vec2{5, 3}); c != (RGBA{0.5, 0, 0.5, 1}) {
+		t.Fatalf("Unexpected middle color: %v", c)
+	}
+	if c := g.At(Vec2{-5, 0}); c != (RGBA{1, 0, 0, 1}) {
+		t.Fatalf("Expected the first stop before the start: %v", c)
+	}
+
+	r := NewRadialGradient(Vec2{0, 0}, 2, GradientStop{0, White}, GradientStop{1, Black})
+	if c := r.At(Vec2{0, 1}); c != (RGBA{0.5, 0.5, 0.5, 1}) {
+		t.Fatalf("Unexpected radial color: %v", c)
+	}
+}
+
+func TestGradientSplitsStops(t *testing.T) {
+	g := NewGeomDraw()
+	g.SetGradient(NewLinearGradient(Vec2{0, 0}, Vec2{10, 0},
+		GradientStop{0, RGBA{1, 0, 0, 1}},
+		GradientStop{0.5, RGBA{0, 1, 0, 1}},
+		GradientStop{1, RGBA{0, 0, 1, 1}},
+	))
+	mesh := g.FillRect(R(0, 0, 10, 10))
+
+	// The middle stop needs vertices along x = 5 to show up
+	found := false
+	for i, p := range mesh.positions {
+		if p[0] == 5 {
+			found = true
+			if mesh.colors[i] != (Vec4{0, 1, 0, 1}) {
+				t.Fatalf("Unexpected color at the middle stop: %v", mesh.colors[i])
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("The mesh wasn't split at the middle stop")
+	}
+	checkArea(t, triangleArea(mesh.positions, mesh.indices), 100)
+}
+
+func TestRoundedRect(t *testing.T) {
+	g := NewGeomDraw()
+	g.Divisions = 4000
+	mesh := g.RoundedRect(R(0, 0, 20, 10), Radii(2), 0)
+	want := float32(200 - (4 - math.Pi) * 4)
+	if math.Abs(float64(triangleArea(mesh.positions, mesh.indices) - want)) > 0.01 {
+		t.Fatalf("Area: got %v, want %v", triangleArea(mesh.positions, mesh.indices), want)
+	}
+
+	// Radii which don't fit are scaled down to make a stadium shape
+	mesh = g.RoundedRect(R(0, 0, 20, 10), Radii(100), 0)
+	want = float32(10 * 10 + math.Pi * 25)
+	if math.Abs(float64(triangleArea(mesh.positions, mesh.indices) - want)) > 0.01 {
+		t.Fatalf("Area: got %v, want %v", triangleArea(mesh.positions, mesh.indices), want)
+	}
+
+	// Outlines are drawn inside of the rect
+	mesh = g.RoundedRect(R(0, 0, 20, 10), Radii(0), 1)
+	checkArea(t, triangleArea(mesh.positions, mesh.indices), 200 - (18 * 8))
+}
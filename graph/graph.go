@@ -20,9 +20,15 @@ func NewGraph(bounds glitch.Rect) *Graph {
 		mesh: glitch.NewMesh(),
 		bounds: bounds,
 	}
+	g.geom.Feather = 1
 	return g
 }
 
+// Sets how far the edges of lines are faded out to anti alias them. This should be about one pixel, so scale it if the graph is zoomed. 0 draws hard edges
+func (g *Graph) SetFeather(feather float32) {
+	g.geom.Feather = feather
+}
+
 func (g *Graph) Clear() {
 	g.mesh.Clear()
 }
@@ -0,0 +1,256 @@
+package glitch
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/faiface/mainthread"
+	"github.com/unitoftime/gl"
+)
+
+// A 2D point or spot light
+type Light2D struct {
+	Position Vec2
+	Color RGBA // Values above 1 make the light brighter
+	Radius float32 // The light doesn't reach past this distance
+	Falloff float32 // The exponent of the attenuation curve. 1 is linear, 2 is quadratic. 0 is treated as 1
+	Softness float32 // The radius of the light source, which blurs the edges of its shadows. 0 casts hard shadows
+
+	// Spot lights
+	Angle float32 // The full angle of the cone in radians. 0 (or anything >= 2 Pi) makes a point light
+	Direction float32 // The direction the cone points in radians
+}
+
+// Accumulates 2D lights into a light frame, which is then multiplied over the scene (See Composite).
+// Occluders block the light with stencil shadow volumes: Every edge of an occluder is extruded away from the light and the light is only drawn where no extrusion landed.
+// Soft shadows are made by drawing each light multiple times from points spread over its Softness radius
+type LightPass struct {
+	frame *Frame
+	pass *RenderPass
+	composite *PostEffect
+	lights []Light2D
+	occluders [][]Vec3
+
+	Ambient RGBA // The light level of areas that no light reaches
+	ShadowSamples int // The number of times a light with Softness is drawn
+}
+
+// The light shader is drawn with sprite style attributes (See shaders.Light2DShader) and the composite shader is a post effect which copies its input (See shaders.CopyShader)
+func NewLightPass(bounds Rect, lightShader, compositeShader *Shader) (*LightPass, error) {
+	frame, err := NewFrameExt(bounds, FrameConfig{
+		Texture: SmoothTextureConfig(true),
+		Stencil: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("NewLightPass: %w", err)
+	}
+
+	return &LightPass{
+		frame: frame,
+		pass: NewRenderPass(lightShader),
+		composite: NewPostEffect(compositeShader),
+		lights: make([]Light2D, 0),
+		occluders: make([][]Vec3, 0),
+		Ambient: RGBA{0.1, 0.1, 0.1, 1.0},
+		ShadowSamples: 8,
+	}, nil
+}
+
+// Returns the frame that the lights are accumulated into
+func (l *LightPass) Frame() *Frame {
+	return l.frame
+}
+
+func (l *LightPass) Resize(bounds Rect) {
+	l.frame.Resize(bounds)
+}
+
+func (l *LightPass) TrackWindow(win *Window) {
+	l.frame.TrackWindow(win)
+}
+
+// Sets the camera that lights and occluders are positioned with
+func (l *LightPass) SetCamera(projection, view Mat4) {
+	l.pass.SetUniform("projection", projection)
+	l.pass.SetUniform("view", view)
+}
+
+// Removes all lights and occluders
+func (l *LightPass) Clear() {
+	l.lights = l.lights[:0]
+	l.occluders = l.occluders[:0]
+}
+
+func (l *LightPass) AddLight(light Light2D) {
+	l.lights = append(l.lights, light)
+}
+
+// Adds a closed polygon outline (like the points given to GeomDraw.Polygon) which casts shadows
+func (l *LightPass) AddOccluder(points []Vec3) {
+	if len(points) < 2 { return }
+	l.occluders = append(l.occluders, points)
+}
+
+// A unit quad with texture coordinates running from -1 to 1, so the light shader can compute the distance from the center
+var lightQuad = NewQuadMesh(R(-1, -1, 1, 1), R(-1, 1, 1, -1))
+
+// Draws all of the lights into the light frame
+func (l *LightPass) Draw() {
+	Clear(l.frame, l.Ambient)
+
+	mainthread.Call(func() {
+		gl.Enable(gl.STENCIL_TEST)
+	})
+
+	material := DefaultMaterial()
+	for _, light := range l.lights {
+		if light.Radius <= 0 { continue }
+
+		falloff := light.Falloff
+		if falloff <= 0 {
+			falloff = 1
+		}
+		l.pass.SetUniform("falloff", falloff)
+
+		// Spot lights are limited to the cone, with a soft edge over the outer fifth of it
+		spotCos := float32(-2) // Never limits the light
+		if light.Angle > 0 && light.Angle < 2 * math.Pi {
+			spotCos = float32(math.Cos(float64(light.Angle / 2)))
+		}
+		l.pass.SetUniform("spotCos", spotCos)
+		l.pass.SetUniform("spotEdge", (1 - spotCos) * 0.2)
+		l.pass.SetUniform("spotDirection", Vec2{
+			float32(math.Cos(float64(light.Direction))),
+			float32(math.Sin(float64(light.Direction))),
+		})
+
+		occluders := l.occludersInRange(light)
+		samples := 1
+		if light.Softness > 0 && len(occluders) > 0 && l.ShadowSamples > 1 {
+			samples = l.ShadowSamples
+		}
+
+		mask := RGBA{
+			light.Color.R / float32(samples),
+			light.Color.G / float32(samples),
+			light.Color.B / float32(samples),
+			1.0,
+		}
+
+		for s := 0; s < samples; s++ {
+			source := light.Position
+			if samples > 1 {
+				// Spread the samples over a disc with a golden angle spiral
+				r := light.Softness * float32(math.Sqrt((float64(s) + 0.5) / float64(samples)))
+				theta := float64(s) * 2.39996323
+				source = source.Add(Vec2{r * float32(math.Cos(theta)), r * float32(math.Sin(theta))})
+			}
+
+			// Mark the shadowed areas in the stencil buffer
+			mainthread.Call(func() {
+				gl.ClearStencil(0)
+				gl.Clear(gl.STENCIL_BUFFER_BIT)
+				gl.ColorMask(false, false, false, false)
+				stencilFunc(gl.ALWAYS, 1, 0xFF)
+				stencilOp(gl.KEEP, gl.KEEP, gl.REPLACE)
+			})
+			if len(occluders) > 0 {
+				l.pass.Clear()
+				l.pass.Add(shadowVolumes(source, light.Radius, occluders), Mat4Ident, RGBA{1, 1, 1, 1}, material)
+				l.pass.Draw(l.frame)
+			}
+
+			// Add the light wherever the stencil buffer is still clear
+			mainthread.Call(func() {
+				gl.ColorMask(true, true, true, true)
+				stencilFunc(gl.EQUAL, 0, 0xFF)
+				stencilOp(gl.KEEP, gl.KEEP, gl.KEEP)
+				gl.BlendFunc(gl.ONE, gl.ONE)
+			})
+			matrix := Mat4Ident
+			matrix.Scale(light.Radius, light.Radius, 1).Translate(light.Position[0], light.Position[1], 0)
+			l.pass.Clear()
+			l.pass.Add(lightQuad, matrix, mask, material)
+			l.pass.Draw(l.frame)
+
+			mainthread.Call(func() {
+				gl.BlendFunc(gl.ONE, gl.ONE_MINUS_SRC_ALPHA) // Premult
+			})
+		}
+	}
+
+	mainthread.Call(func() {
+		gl.Disable(gl.STENCIL_TEST)
+	})
+}
+
+// Multiplies the light frame over everything that has been drawn to the target
+func (l *LightPass) Composite(target Target) {
+	mainthread.Call(func() {
+		gl.BlendFunc(gl.DST_COLOR, gl.ZERO)
+	})
+
+	tex := l.frame.Texture()
+	l.composite.Draw(target, tex, tex)
+
+	mainthread.Call(func() {
+		gl.BlendFunc(gl.ONE, gl.ONE_MINUS_SRC_ALPHA) // Premult
+	})
+}
+
+// Returns the occluders whose bounding boxes overlap the light
+func (l *LightPass) occludersInRange(light Light2D) [][]Vec3 {
+	reach := light.Radius + light.Softness
+	lightRect := R(light.Position[0] - reach, light.Position[1] - reach, light.Position[0] + reach, light.Position[1] + reach)
+
+	ret := make([][]Vec3, 0, len(l.occluders))
+	for _, points := range l.occluders {
+		rect := R(points[0][0], points[0][1], points[0][0], points[0][1])
+		for _, p := range points[1:] {
+			rect = rect.Union(R(p[0], p[1], p[0], p[1]))
+		}
+		if rect.Max[0] < lightRect.Min[0] || rect.Min[0] > lightRect.Max[0] { continue }
+		if rect.Max[1] < lightRect.Min[1] || rect.Min[1] > lightRect.Max[1] { continue }
+		ret = append(ret, points)
+	}
+	return ret
+}
+
+// Builds a quad for every occluder edge, stretching from the edge to well past the light's radius in the direction away from the light
+func shadowVolumes(source Vec2, radius float32, occluders [][]Vec3) *Mesh {
+	far := radius * 8
+
+	mesh := NewMesh()
+	for _, points := range occluders {
+		for i := range points {
+			a := points[i].Vec2()
+			b := points[(i + 1) % len(points)].Vec2()
+
+			aFar := a.Add(shadowDir(source, a).Scaled(far))
+			bFar := b.Add(shadowDir(source, b).Scaled(far))
+
+			start := uint32(len(mesh.positions))
+			mesh.positions = append(mesh.positions, a.Vec3(), b.Vec3(), bFar.Vec3(), aFar.Vec3())
+			for j := 0; j < 4; j++ {
+				mesh.colors = append(mesh.colors, Vec4{1, 1, 1, 1})
+				mesh.texCoords = append(mesh.texCoords, Vec2{0, 0})
+			}
+			mesh.indices = append(mesh.indices,
+				start + 0, start + 1, start + 2,
+				start + 0, start + 2, start + 3,
+			)
+		}
+	}
+	mesh.RecalculateBounds()
+	return mesh
+}
+
+// Returns the unit direction from the light to the point
+func shadowDir(source, point Vec2) Vec2 {
+	dir := point.Sub(source)
+	length := dir.Len()
+	if length == 0 {
+		return Vec2{0, 0}
+	}
+	return dir.Scaled(1 / length)
+}
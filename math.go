@@ -42,6 +42,15 @@ func (v Vec3) Dot(u Vec3) float32 {
 	return (v[0] * u[0]) + (v[1] * u[1]) + (v[2] * u[2])
 }
 
+// Finds the cross product of two vectors
+func (v Vec3) Cross(u Vec3) Vec3 {
+	return Vec3{
+		(v[1] * u[2]) - (v[2] * u[1]),
+		(v[2] * u[0]) - (v[0] * u[2]),
+		(v[0] * u[1]) - (v[1] * u[0]),
+	}
+}
+
 // Finds the angle between two vectors
 func (v Vec3) Angle(u Vec3) float32 {
 	return  float32(math.Acos(float64(v.Dot(u) / (v.Len() * u.Len()))))
@@ -251,6 +260,28 @@ func (a Box) Union(b Box) Box {
 	}
 }
 
+// Returns the smallest box which contains this box after it is transformed by the matrix
+func (b Box) Transform(m Mat4) Box {
+	corners := [8]Vec3{
+		Vec3{b.Min[0], b.Min[1], b.Min[2]},
+		Vec3{b.Max[0], b.Min[1], b.Min[2]},
+		Vec3{b.Min[0], b.Max[1], b.Min[2]},
+		Vec3{b.Max[0], b.Max[1], b.Min[2]},
+		Vec3{b.Min[0], b.Min[1], b.Max[2]},
+		Vec3{b.Max[0], b.Min[1], b.Max[2]},
+		Vec3{b.Min[0], b.Max[1], b.Max[2]},
+		Vec3{b.Max[0], b.Max[1], b.Max[2]},
+	}
+
+	first := m.Apply(corners[0])
+	ret := Box{first, first}
+	for _, c := range corners[1:] {
+		p := m.Apply(c)
+		ret = ret.Union(Box{p, p})
+	}
+	return ret
+}
+
 type Rect struct {
 	Min, Max Vec2
 }
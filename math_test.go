@@ -27,3 +27,20 @@ func TestRotate(t *testing.T) {
 	b := a.Rotate2D(3.14159/2)
 	fmt.Println(b)
 }
+
+func TestBoxTransform(t *testing.T) {
+	box := Box{Vec3{-1, -1, 0}, Vec3{1, 1, 0}}
+	mat := Mat4Ident
+	mat.Scale(2, 3, 1).Translate(10, 20, 5)
+	if b := box.Transform(mat); b != (Box{Vec3{8, 17, 5}, Vec3{12, 23, 5}}) {
+		t.Fatalf("Unexpected box: %v", b)
+	}
+
+	// A 45 degree rotation grows the box around the rotated corners
+	mat = Mat4Ident
+	mat.Rotate(3.14159265/4, Vec3{0, 0, 1})
+	b := box.Transform(mat)
+	if b.Max[0] < 1.41 || b.Max[0] > 1.42 || b.Min[1] > -1.41 || b.Min[1] < -1.42 {
+		t.Fatalf("Unexpected rotated box: %v", b)
+	}
+}
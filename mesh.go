@@ -1,5 +1,9 @@
 package glitch
 
+import (
+	"math"
+)
+
 // For batching multiple sprites into one
 type Batch struct {
 	mesh *Mesh
@@ -34,6 +38,14 @@ func (b *Batch) Add(mesh *Mesh, matrix Mat4, mask RGBA, material Material) {
 		normBuf[i] = renormalizeMat.Apply(mesh.normals[i])
 	}
 
+	origin := matrix.Apply(Vec3{0, 0, 0})
+	tanBuf := make([]Vec4, len(mesh.tangents))
+	for i := range mesh.tangents {
+		t := mesh.tangents[i]
+		vec := matrix.Apply(Vec3{t[0], t[1], t[2]}).Sub(origin)
+		tanBuf[i] = Vec4{vec[0], vec[1], vec[2], t[3]}
+	}
+
 	colBuf := make([]Vec4, len(mesh.colors))
 	for i := range mesh.colors {
 		// TODO - vec4 mult function
@@ -59,10 +71,11 @@ func (b *Batch) Add(mesh *Mesh, matrix Mat4, mask RGBA, material Material) {
 	m2 := &Mesh{
 		positions: posBuf,
 		normals: normBuf,
+		tangents: tanBuf,
 		colors: colBuf,
 		texCoords: texBuf,
 		indices: indices,
-		//bounds: todo,
+		bounds: positionBounds(posBuf),
 	}
 
 	b.mesh.Append(m2)
@@ -80,6 +93,7 @@ func (b *Batch) Draw(target BatchTarget, matrix Mat4) {
 type Mesh struct {
 	positions []Vec3
 	normals []Vec3
+	tangents []Vec4
 	colors []Vec4
 	texCoords []Vec2
 	indices []uint32
@@ -91,6 +105,7 @@ func NewMesh() *Mesh {
 	return &Mesh{
 		positions: make([]Vec3, 0),
 		normals: make([]Vec3, 0),
+		tangents: make([]Vec4, 0),
 		colors: make([]Vec4, 0),
 		texCoords: make([]Vec2, 0),
 		indices: make([]uint32, 0),
@@ -101,6 +116,7 @@ func NewMesh() *Mesh {
 func (m *Mesh) Clear() {
 	m.positions = m.positions[:0]
 	m.normals = m.normals[:0]
+	m.tangents = m.tangents[:0]
 	m.colors = m.colors[:0]
 	m.texCoords = m.texCoords[:0]
 	m.indices = m.indices[:0]
@@ -120,8 +136,163 @@ func (m *Mesh) Bounds() Box {
 	return m.bounds
 }
 
+// Returns the bounds of the mesh after it is transformed by the matrix (Ex: the matrix it is drawn with).
+// This is a box around the transformed bounds, so it can be a bit bigger than the transformed mesh when rotated
+func (m *Mesh) TransformedBounds(matrix Mat4) Box {
+	return m.bounds.Transform(matrix)
+}
+
+// Recalculates the bounds from the positions. Call this after moving vertices
+func (m *Mesh) RecalculateBounds() {
+	m.bounds = positionBounds(m.positions)
+}
+
+// Calculates a tangent for every vertex from the positions, normals and texture coordinates, for normal mapping.
+// The tangent points along increasing u, and w is the sign of the bitangent (increasing v), so that bitangent = cross(normal, tangent) * w.
+// Vertices which are only used by triangles with degenerate texture coordinates get an arbitrary tangent that is perpendicular to the normal
+func (m *Mesh) RecalculateTangents() {
+	count := len(m.positions)
+	tangents := make([]Vec3, count)
+	bitangents := make([]Vec3, count)
+
+	if len(m.texCoords) == count {
+		for i := 0; i + 2 < len(m.indices); i += 3 {
+			i0, i1, i2 := m.indices[i], m.indices[i+1], m.indices[i+2]
+			e1 := m.positions[i1].Sub(m.positions[i0])
+			e2 := m.positions[i2].Sub(m.positions[i0])
+			uv1 := m.texCoords[i1].Sub(m.texCoords[i0])
+			uv2 := m.texCoords[i2].Sub(m.texCoords[i0])
+
+			det := (uv1[0] * uv2[1]) - (uv2[0] * uv1[1])
+			if det == 0 { continue }
+			r := 1 / det
+
+			// Solve e1 = du1 * T + dv1 * B and e2 = du2 * T + dv2 * B
+			t := e1.Scaled(uv2[1], uv2[1], uv2[1]).Sub(e2.Scaled(uv1[1], uv1[1], uv1[1])).Scaled(r, r, r)
+			b := e2.Scaled(uv1[0], uv1[0], uv1[0]).Sub(e1.Scaled(uv2[0], uv2[0], uv2[0])).Scaled(r, r, r)
+			for _, idx := range []uint32{i0, i1, i2} {
+				tangents[idx] = tangents[idx].Add(t)
+				bitangents[idx] = bitangents[idx].Add(b)
+			}
+		}
+	}
+
+	m.tangents = m.tangents[:0]
+	for i := 0; i < count; i++ {
+		var normal Vec3
+		if i < len(m.normals) {
+			normal = m.normals[i]
+		}
+
+		// Make the tangent perpendicular to the normal
+		t := tangents[i]
+		d := normal.Dot(t)
+		t = t.Sub(normal.Scaled(d, d, d))
+		if t.Len() < 1e-6 {
+			t = perpendicular(normal)
+		}
+		t = t.Unit()
+
+		w := float32(1)
+		if normal.Cross(t).Dot(bitangents[i]) < 0 {
+			w = -1
+		}
+		m.tangents = append(m.tangents, Vec4{t[0], t[1], t[2], w})
+	}
+}
+
+// Returns a unit vector perpendicular to v
+func perpendicular(v Vec3) Vec3 {
+	if v.Len() == 0 {
+		return Vec3{1, 0, 0}
+	}
+	axis := Vec3{1, 0, 0}
+	if math.Abs(float64(v[0])) > 0.9 * float64(v.Len()) {
+		axis = Vec3{0, 1, 0}
+	}
+	return v.Cross(axis).Unit()
+}
+
+// Returns the number of vertices in the mesh
+func (m *Mesh) VertexCount() int {
+	return len(m.positions)
+}
+
+// Returns all of the attributes of a vertex. The normal and tangent are zero if the mesh doesn't have them
+func (m *Mesh) Vertex(i int) Vertex {
+	v := Vertex{
+		Position: m.positions[i],
+	}
+	if i < len(m.normals) {
+		v.Normal = m.normals[i]
+	}
+	if i < len(m.tangents) {
+		v.Tangent = m.tangents[i]
+	}
+	if i < len(m.colors) {
+		c := m.colors[i]
+		v.Color = RGBA{c[0], c[1], c[2], c[3]}
+	}
+	if i < len(m.texCoords) {
+		v.TexCoord = m.texCoords[i]
+	}
+	return v
+}
+
+// The accessors below return the mesh's own buffers, so vertices can be read and modified in place.
+// Call RecalculateBounds after changing positions. Changes show up the next time the mesh is added to a RenderPass
+
+func (m *Mesh) Positions() []Vec3 {
+	return m.positions
+}
+
+// Returns the normals, which are empty for meshes that don't have them (Ex: 2D shapes)
+func (m *Mesh) Normals() []Vec3 {
+	return m.normals
+}
+
+// Returns the tangents, which are empty for meshes that don't have them (See RecalculateTangents)
+func (m *Mesh) Tangents() []Vec4 {
+	return m.tangents
+}
+
+// Returns the premultiplied vertex colors
+func (m *Mesh) Colors() []Vec4 {
+	return m.colors
+}
+
+func (m *Mesh) TexCoords() []Vec2 {
+	return m.texCoords
+}
+
+// Returns the triangle list, three indices per triangle
+func (m *Mesh) Indices() []uint32 {
+	return m.indices
+}
+
+// Returns the smallest box which contains all of the positions
+func positionBounds(positions []Vec3) Box {
+	if len(positions) <= 0 {
+		return Box{}
+	}
+	bounds := Box{positions[0], positions[0]}
+	for _, p := range positions[1:] {
+		bounds = bounds.Union(Box{p, p})
+	}
+	return bounds
+}
+
 // TODO - should this be more like draw?
 func (m *Mesh) Append(m2 *Mesh) {
+	// Empty meshes have a zero box at the origin, which shouldn't be part of the union
+	if len(m2.positions) > 0 {
+		if len(m.positions) == 0 {
+			m.bounds = m2.bounds
+		} else {
+			m.bounds = m.bounds.Union(m2.bounds)
+		}
+	}
+
 	currentElement := uint32(len(m.positions))
 	for i := range m2.indices {
 		m.indices = append(m.indices, currentElement + m2.indices[i])
@@ -129,10 +300,9 @@ func (m *Mesh) Append(m2 *Mesh) {
 
 	m.positions = append(m.positions, m2.positions...)
 	m.normals = append(m.normals, m2.normals...)
+	m.tangents = append(m.tangents, m2.tangents...)
 	m.colors = append(m.colors, m2.colors...)
 	m.texCoords = append(m.texCoords, m2.texCoords...)
-
-	m.bounds = m.bounds.Union(m2.bounds)
 }
 
 // func (m *Mesh) SetTranslation(pos Vec3) {
@@ -209,138 +379,3 @@ func NewQuadMesh(bounds Rect, uvBounds Rect) *Mesh {
 		bounds: bounds.ToBox(),
 	}
 }
-
-func NewCubeMesh(size float32) *Mesh {
-	size = size/2
-
-	positions := []Vec3{
-		// Front face
-		Vec3{-size, -size,  size},
-		Vec3{size, -size,  size},
-		Vec3{size,  size,  size},
-		Vec3{-size,  size,  size},
-		// Back face
-		Vec3{-size, -size, -size},
-		Vec3{-size,  size, -size},
-		Vec3{size,  size, -size},
-		Vec3{size, -size, -size},
-		// Top face
-		Vec3{-size,  size, -size},
-		Vec3{-size,  size,  size},
-		Vec3{size,  size,  size},
-		Vec3{size,  size, -size},
-		// Bottom face
-		Vec3{-size, -size, -size},
-		Vec3{size, -size, -size},
-		Vec3{size, -size,  size},
-		Vec3{-size, -size,  size},
-		// Right face
-		Vec3{size, -size, -size},
-		Vec3{size,  size, -size},
-		Vec3{size,  size,  size},
-		Vec3{size, -size,  size},
-		// Left face
-		Vec3{-size, -size, -size},
-		Vec3{-size, -size,  size},
-		Vec3{-size,  size,  size},
-		Vec3{-size,  size, -size},
-	}
-
-	col := Vec4{1.0, 1.0, 1.0, 1.0}
-	colors := []Vec4{
-		col, col, col, col,
-		col, col, col, col,
-		col, col, col, col,
-		col, col, col, col,
-		col, col, col, col,
-	}
-
-	// TODO normals
-	normals := []Vec3{
-		// Front face
-		Vec3{0, 0, 1},
-		Vec3{0, 0, 1},
-		Vec3{0, 0, 1},
-		Vec3{0, 0, 1},
-		// Back face
-		Vec3{0, 0, -1},
-		Vec3{0, 0, -1},
-		Vec3{0, 0, -1},
-		Vec3{0, 0, -1},
-		// Top face
-		Vec3{0, 1, 0},
-		Vec3{0, 1, 0},
-		Vec3{0, 1, 0},
-		Vec3{0, 1, 0},
-		// Bottom face
-		Vec3{0, -1, 0},
-		Vec3{0, -1, 0},
-		Vec3{0, -1, 0},
-		Vec3{0, -1, 0},
-		// Right face
-		Vec3{1, 0, 0},
-		Vec3{1, 0, 0},
-		Vec3{1, 0, 0},
-		Vec3{1, 0, 0},
-		// Left face
-		Vec3{-1, 0, 0},
-		Vec3{-1, 0, 0},
-		Vec3{-1, 0, 0},
-		Vec3{-1, 0, 0},
-	}
-
-	// TODO texCoords
-	texCoords := []Vec2{
-		// Front face
-		Vec2{-0, -0},
-		Vec2{0, -0},
-		Vec2{0,  0},
-		Vec2{-0,  0},
-		// Back face
-		Vec2{-0, -0},
-		Vec2{-0,  0},
-		Vec2{0,  0},
-		Vec2{0, -0},
-		// Top face
-		Vec2{-0,  0},
-		Vec2{-0,  0},
-		Vec2{0,  0},
-		Vec2{0,  0},
-		// Bottom face
-		Vec2{-0, -0},
-		Vec2{0, -0},
-		Vec2{0, -0},
-		Vec2{-0, -0},
-		// Right face
-		Vec2{0, -0},
-		Vec2{0,  0},
-		Vec2{0,  0},
-		Vec2{0, -0},
-		// Left face
-		Vec2{-0, -0},
-		Vec2{-0, -0},
-		Vec2{-0,  0},
-		Vec2{-0,  0},
-	}
-
-	indices := []uint32{
-		0,  1,  2,      0,  2,  3,    // front
-    4,  5,  6,      4,  6,  7,    // back
-    8,  9,  10,     8,  10, 11,   // top
-    12, 13, 14,     12, 14, 15,   // bottom
-    16, 17, 18,     16, 18, 19,   // right
-    20, 21, 22,     20, 22, 23,   // left
-	}
-
-	return &Mesh{
-		positions: positions,
-		normals: normals,
-		colors: colors,
-		texCoords: texCoords,
-		indices: indices,
-		bounds: Box{
-			Min: Vec3{-size, -size, -size},
-			Max: Vec3{size, size, size},
-		},
-	}
-}
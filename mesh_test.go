@@ -0,0 +1,36 @@
+package glitch
+
+import (
+	"testing"
+)
+
+func TestBatchBounds(t *testing.T) {
+	material := &SpriteMaterial{} // Doesn't need a texture because the batch is never drawn
+	batch := NewBatch()
+	mat := Mat4Ident
+	mat.Translate(10, 10, 0)
+	batch.Add(NewQuadMesh(R(0, 0, 2, 2), R(0, 0, 1, 1)), mat, White, material)
+	mat = Mat4Ident
+	mat.Translate(20, 5, 0)
+	batch.Add(NewQuadMesh(R(0, 0, 2, 2), R(0, 0, 1, 1)), mat, White, material)
+
+	if b := batch.mesh.Bounds(); b != (R(10, 5, 22, 12).ToBox()) {
+		t.Fatalf("Unexpected batch bounds: %v", b)
+	}
+}
+
+func TestAppendBounds(t *testing.T) {
+	mesh := NewMesh()
+	mesh.Append(NewMesh()) // Empty meshes don't add the origin
+	mesh.Append(NewQuadMesh(R(5, 5, 6, 6), R(0, 0, 1, 1)))
+	mesh.Append(NewMesh())
+	if b := mesh.Bounds(); b != (R(5, 5, 6, 6).ToBox()) {
+		t.Fatalf("Unexpected bounds: %v", b)
+	}
+
+	mat := Mat4Ident
+	mat.Translate(-5, -5, 0)
+	if b := mesh.TransformedBounds(mat); b != (R(0, 0, 1, 1).ToBox()) {
+		t.Fatalf("Unexpected transformed bounds: %v", b)
+	}
+}
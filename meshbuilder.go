@@ -0,0 +1,64 @@
+package glitch
+
+import (
+	"fmt"
+)
+
+// All of the attributes of a single mesh vertex
+type Vertex struct {
+	Position Vec3
+	Normal Vec3
+	Tangent Vec4 // The w component is the sign of the bitangent
+	Color RGBA
+	TexCoord Vec2
+}
+
+// Builds meshes one vertex and triangle at a time. Useful for procedural geometry
+type MeshBuilder struct {
+	mesh *Mesh
+}
+
+func NewMeshBuilder() *MeshBuilder {
+	return &MeshBuilder{
+		mesh: NewMesh(),
+	}
+}
+
+// Adds a vertex and returns its index, for use in AddTriangle
+func (b *MeshBuilder) AddVertex(v Vertex) uint32 {
+	m := b.mesh
+	m.positions = append(m.positions, v.Position)
+	m.normals = append(m.normals, v.Normal)
+	m.tangents = append(m.tangents, v.Tangent)
+	m.colors = append(m.colors, Vec4{v.Color.R, v.Color.G, v.Color.B, v.Color.A})
+	m.texCoords = append(m.texCoords, v.TexCoord)
+	return uint32(len(m.positions) - 1)
+}
+
+// Adds a triangle between three vertices, which should be counter clockwise to face forward
+func (b *MeshBuilder) AddTriangle(v0, v1, v2 uint32) {
+	count := uint32(len(b.mesh.positions))
+	if v0 >= count || v1 >= count || v2 >= count {
+		panic(fmt.Sprintf("MeshBuilder: triangle (%d, %d, %d) references a vertex that hasn't been added. Vertex count: %d", v0, v1, v2, count))
+	}
+	b.mesh.indices = append(b.mesh.indices, v0, v1, v2)
+}
+
+// Adds two triangles to fill the quad between four vertices, which should be in counter clockwise order
+func (b *MeshBuilder) AddQuad(v0, v1, v2, v3 uint32) {
+	b.AddTriangle(v0, v1, v2)
+	b.AddTriangle(v0, v2, v3)
+}
+
+// Returns the number of vertices that have been added
+func (b *MeshBuilder) VertexCount() int {
+	return len(b.mesh.positions)
+}
+
+// Returns the finished mesh, with its bounds calculated. The builder is reset so it can start on a new mesh
+func (b *MeshBuilder) Build() *Mesh {
+	mesh := b.mesh
+	mesh.RecalculateBounds()
+	b.mesh = NewMesh()
+	return mesh
+}
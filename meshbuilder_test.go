@@ -0,0 +1,56 @@
+package glitch
+
+import (
+	"testing"
+)
+
+func TestMeshBuilder(t *testing.T) {
+	b := NewMeshBuilder()
+	v0 := b.AddVertex(Vertex{Position: Vec3{-1, 0, 2}, Normal: Vec3{0, 0, 1}, Color: White, TexCoord: Vec2{0, 1}})
+	v1 := b.AddVertex(Vertex{Position: Vec3{3, 0, 2}, Normal: Vec3{0, 0, 1}, Color: White, TexCoord: Vec2{1, 1}})
+	v2 := b.AddVertex(Vertex{Position: Vec3{3, 4, 2}, Normal: Vec3{0, 0, 1}, Color: White, TexCoord: Vec2{1, 0}})
+	v3 := b.AddVertex(Vertex{Position: Vec3{-1, 4, 2}, Normal: Vec3{0, 0, 1}, Color: Black, TexCoord: Vec2{0, 0}})
+	b.AddQuad(v0, v1, v2, v3)
+
+	mesh := b.Build()
+	if mesh.VertexCount() != 4 || len(mesh.Indices()) != 6 {
+		t.Fatalf("Unexpected mesh size: %d vertices, %d indices", mesh.VertexCount(), len(mesh.Indices()))
+	}
+	if mesh.Bounds() != (Box{Vec3{-1, 0, 2}, Vec3{3, 4, 2}}) {
+		t.Fatalf("Unexpected bounds: %v", mesh.Bounds())
+	}
+	if v := mesh.Vertex(3); v.Color != Black || v.Normal != (Vec3{0, 0, 1}) {
+		t.Fatalf("Unexpected vertex: %v", v)
+	}
+	if b.VertexCount() != 0 {
+		t.Fatalf("Builder wasn't reset")
+	}
+
+	// Deform in place
+	mesh.Positions()[0][0] = -5
+	mesh.RecalculateBounds()
+	if mesh.Bounds().Min[0] != -5 {
+		t.Fatalf("Bounds weren't recalculated: %v", mesh.Bounds())
+	}
+}
+
+func TestMeshBuilderBadIndex(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Expected a panic")
+		}
+	}()
+	b := NewMeshBuilder()
+	b.AddVertex(Vertex{})
+	b.AddTriangle(0, 1, 2)
+}
+
+func TestGeomDrawBounds(t *testing.T) {
+	g := NewGeomDraw()
+	if b := g.FillRect(R(2, 3, 4, 5)).Bounds(); b != (R(2, 3, 4, 5).ToBox()) {
+		t.Fatalf("Unexpected FillRect bounds: %v", b)
+	}
+	if b := g.Rectangle(R(2, 3, 4, 5), 0.5).Bounds(); b != (R(2, 3, 4, 5).ToBox()) {
+		t.Fatalf("Unexpected Rectangle bounds: %v", b)
+	}
+}
@@ -0,0 +1,388 @@
+package glitch
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// A material from a Wavefront MTL file. Only the diffuse properties are used
+type ObjMaterial struct {
+	Name string
+	Diffuse RGBA // The Kd color, with the d (or 1 - Tr) alpha. White if unset
+	DiffuseMap string // The map_Kd texture path, relative to the MTL file. Empty if unset
+}
+
+// The faces of an OBJ file that use one material
+type ObjGroup struct {
+	Material string // Empty for faces that come before any usemtl
+	Mesh *Mesh
+}
+
+// The geometry of a Wavefront OBJ file, split up by material
+type ObjFile struct {
+	MaterialLibs []string // The mtllib paths, relative to the OBJ file
+	Groups []ObjGroup // In the order that their materials were first used
+}
+
+// A face corner, as indices into the position, texture coordinate and normal lists. -1 if it is missing
+type objIndex struct {
+	v, vt, vn int
+}
+
+type objGroupBuilder struct {
+	material string
+	builder *MeshBuilder
+	vertices map[objIndex]uint32 // Lets face corners with the same indices share a vertex
+}
+
+// Parses the geometry of a Wavefront OBJ file. Polygons are split into triangle fans, and faces without normals get flat normals.
+// Texture coordinates are flipped so that v = 0 is the top of the image, like the rest of glitch. Vertex colors (v x y z r g b) are supported, and other vertices are white
+func DecodeOBJ(r io.Reader) (*ObjFile, error) {
+	var positions []Vec3
+	var colors []RGBA
+	var texCoords []Vec2
+	var normals []Vec3
+
+	obj := &ObjFile{}
+	groups := make(map[string]*objGroupBuilder)
+	order := make([]*objGroupBuilder, 0)
+	useMaterial := func(name string) *objGroupBuilder {
+		g, ok := groups[name]
+		if !ok {
+			g = &objGroupBuilder{
+				material: name,
+				builder: NewMeshBuilder(),
+				vertices: make(map[objIndex]uint32),
+			}
+			groups[name] = g
+			order = append(order, g)
+		}
+		return g
+	}
+	current := useMaterial("")
+
+	// Converts a 1 based (or negative, relative to the end) index to 0 based
+	resolve := func(field string, count int) (int, error) {
+		idx, err := strconv.Atoi(field)
+		if err != nil {
+			return 0, err
+		}
+		if idx < 0 {
+			idx += count
+		} else {
+			idx--
+		}
+		if idx < 0 || idx >= count {
+			return 0, fmt.Errorf("index %s is out of range", field)
+		}
+		return idx, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64 * 1024), 1024 * 1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 { continue }
+
+		switch fields[0] {
+		case "v":
+			vals, err := parseObjFloats(fields[1:], 3)
+			if err != nil {
+				return nil, fmt.Errorf("DecodeOBJ: line %d: %w", lineNum, err)
+			}
+			positions = append(positions, Vec3{vals[0], vals[1], vals[2]})
+			if len(vals) >= 6 {
+				colors = append(colors, RGBA{vals[3], vals[4], vals[5], 1})
+			} else {
+				colors = append(colors, White)
+			}
+		case "vt":
+			vals, err := parseObjFloats(fields[1:], 1)
+			if err != nil {
+				return nil, fmt.Errorf("DecodeOBJ: line %d: %w", lineNum, err)
+			}
+			v := float32(0)
+			if len(vals) >= 2 {
+				v = vals[1]
+			}
+			texCoords = append(texCoords, Vec2{vals[0], 1 - v})
+		case "vn":
+			vals, err := parseObjFloats(fields[1:], 3)
+			if err != nil {
+				return nil, fmt.Errorf("DecodeOBJ: line %d: %w", lineNum, err)
+			}
+			n := Vec3{vals[0], vals[1], vals[2]}
+			if n.Len() > 0 {
+				n = n.Unit()
+			}
+			normals = append(normals, n)
+		case "f":
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("DecodeOBJ: line %d: faces need at least 3 corners", lineNum)
+			}
+			corners := make([]objIndex, 0, len(fields) - 1)
+			hasNormals := true
+			for _, field := range fields[1:] {
+				parts := strings.Split(field, "/")
+				c := objIndex{-1, -1, -1}
+				var err error
+				c.v, err = resolve(parts[0], len(positions))
+				if err == nil && len(parts) > 1 && parts[1] != "" {
+					c.vt, err = resolve(parts[1], len(texCoords))
+				}
+				if err == nil && len(parts) > 2 && parts[2] != "" {
+					c.vn, err = resolve(parts[2], len(normals))
+				}
+				if err != nil {
+					return nil, fmt.Errorf("DecodeOBJ: line %d: %w", lineNum, err)
+				}
+				if c.vn < 0 {
+					hasNormals = false
+				}
+				corners = append(corners, c)
+			}
+
+			var faceNormal Vec3
+			if !hasNormals {
+				faceNormal = newellNormal(positions, corners)
+			}
+
+			verts := make([]uint32, len(corners))
+			for i, c := range corners {
+				if hasNormals {
+					if idx, ok := current.vertices[c]; ok {
+						verts[i] = idx
+						continue
+					}
+				}
+
+				vert := Vertex{
+					Position: positions[c.v],
+					Normal: faceNormal,
+					Color: colors[c.v],
+				}
+				if c.vt >= 0 {
+					vert.TexCoord = texCoords[c.vt]
+				}
+				if c.vn >= 0 {
+					vert.Normal = normals[c.vn]
+				}
+				verts[i] = current.builder.AddVertex(vert)
+
+				// Flat shaded vertices belong to a single face, so they aren't shared
+				if hasNormals {
+					current.vertices[c] = verts[i]
+				}
+			}
+			for i := 1; i + 1 < len(verts); i++ {
+				current.builder.AddTriangle(verts[0], verts[i], verts[i+1])
+			}
+		case "usemtl":
+			current = useMaterial(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "usemtl")))
+		case "mtllib":
+			obj.MaterialLibs = append(obj.MaterialLibs, fields[1:]...)
+		}
+		// Objects, groups, smoothing groups and everything else are ignored
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("DecodeOBJ: %w", err)
+	}
+
+	for _, g := range order {
+		if g.builder.VertexCount() == 0 { continue }
+		mesh := g.builder.Build()
+		mesh.RecalculateTangents()
+		obj.Groups = append(obj.Groups, ObjGroup{
+			Material: g.material,
+			Mesh: mesh,
+		})
+	}
+	return obj, nil
+}
+
+// Parses the materials of a Wavefront MTL file, in the order they are defined
+func DecodeMTL(r io.Reader) ([]ObjMaterial, error) {
+	materials := make([]ObjMaterial, 0)
+	var current *ObjMaterial
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 { continue }
+
+		if fields[0] == "newmtl" {
+			materials = append(materials, ObjMaterial{
+				Name: strings.TrimSpace(strings.TrimPrefix(line, "newmtl")),
+				Diffuse: White,
+			})
+			current = &materials[len(materials) - 1]
+			continue
+		}
+		if current == nil { continue }
+
+		switch fields[0] {
+		case "Kd":
+			vals, err := parseObjFloats(fields[1:], 3)
+			if err != nil {
+				return nil, fmt.Errorf("DecodeMTL: line %d: %w", lineNum, err)
+			}
+			current.Diffuse.R, current.Diffuse.G, current.Diffuse.B = vals[0], vals[1], vals[2]
+		case "d", "Tr":
+			vals, err := parseObjFloats(fields[1:], 1)
+			if err != nil {
+				return nil, fmt.Errorf("DecodeMTL: line %d: %w", lineNum, err)
+			}
+			current.Diffuse.A = vals[0]
+			if fields[0] == "Tr" {
+				current.Diffuse.A = 1 - vals[0]
+			}
+		case "map_Kd":
+			// Options like -s and -o come before the path, so take the last field
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("DecodeMTL: line %d: map_Kd is missing a path", lineNum)
+			}
+			current.DiffuseMap = fields[len(fields) - 1]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("DecodeMTL: %w", err)
+	}
+	return materials, nil
+}
+
+// Loads an OBJ file along with its MTL files and diffuse textures, and returns a model for each material in the order they were first used.
+// Diffuse textures are loaded into SpriteMaterials with the config, and are decoded with image.Decode, so their formats must be registered (Ex: import _ "image/jpeg").
+// Materials without a texture use DefaultMaterial, and the diffuse color of each material is multiplied into its vertex colors.
+// OBJ files are usually Y up, so models may need to be rotated to stand up in front of a Z up Camera
+func LoadOBJ(fsys fs.FS, name string, config TextureConfig) ([]*Model, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("LoadOBJ: %w", err)
+	}
+	obj, err := DecodeOBJ(file)
+	file.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := path.Dir(name)
+	materials := make(map[string]ObjMaterial)
+	mtlDirs := make(map[string]string) // Texture paths are relative to the MTL file that uses them
+	for _, lib := range obj.MaterialLibs {
+		libPath := path.Join(dir, lib)
+		file, err := fsys.Open(libPath)
+		if err != nil {
+			return nil, fmt.Errorf("LoadOBJ: %w", err)
+		}
+		mtls, err := DecodeMTL(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range mtls {
+			materials[m.Name] = m
+			mtlDirs[m.Name] = path.Dir(libPath)
+		}
+	}
+
+	textures := make(map[string]*Texture) // Materials that share a texture only load it once
+	models := make([]*Model, 0, len(obj.Groups))
+	for _, group := range obj.Groups {
+		mtl, ok := materials[group.Material]
+		if !ok {
+			// Exporters often leave out default materials, so just draw these white
+			models = append(models, NewModel(group.Mesh, DefaultMaterial()))
+			continue
+		}
+
+		tint := mtl.Diffuse
+		for i, c := range group.Mesh.colors {
+			group.Mesh.colors[i] = Vec4{
+				c[0] * tint.R * tint.A,
+				c[1] * tint.G * tint.A,
+				c[2] * tint.B * tint.A,
+				c[3] * tint.A,
+			}
+		}
+
+		if mtl.DiffuseMap == "" {
+			models = append(models, NewModel(group.Mesh, DefaultMaterial()))
+			continue
+		}
+
+		texPath := path.Join(mtlDirs[mtl.Name], strings.ReplaceAll(mtl.DiffuseMap, "\\", "/"))
+		texture, ok := textures[texPath]
+		if !ok {
+			texture, err = loadObjTexture(fsys, texPath, config)
+			if err != nil {
+				return nil, err
+			}
+			textures[texPath] = texture
+		}
+		models = append(models, NewModel(group.Mesh, NewSpriteMaterial(texture)))
+	}
+	return models, nil
+}
+
+func loadObjTexture(fsys fs.FS, name string, config TextureConfig) (*Texture, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("LoadOBJ: %w", err)
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("LoadOBJ: %s: %w", name, err)
+	}
+	return NewTextureExt(img, config), nil
+}
+
+// Parses all of the fields as floats, and requires at least min of them
+func parseObjFloats(fields []string, min int) ([]float32, error) {
+	if len(fields) < min {
+		return nil, fmt.Errorf("expected at least %d values, got %d", min, len(fields))
+	}
+	vals := make([]float32, len(fields))
+	for i, f := range fields {
+		val, err := strconv.ParseFloat(f, 32)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = float32(val)
+	}
+	return vals, nil
+}
+
+// Returns the normal of a polygon that might not be flat, using Newell's method
+func newellNormal(positions []Vec3, corners []objIndex) Vec3 {
+	var n Vec3
+	for i := range corners {
+		a := positions[corners[i].v]
+		b := positions[corners[(i + 1) % len(corners)].v]
+		n[0] += (a[1] - b[1]) * (a[2] + b[2])
+		n[1] += (a[2] - b[2]) * (a[0] + b[0])
+		n[2] += (a[0] - b[0]) * (a[1] + b[1])
+	}
+	if n.Len() == 0 {
+		return n
+	}
+	return n.Unit()
+}
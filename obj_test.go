@@ -0,0 +1,122 @@
+package glitch
+
+import (
+	"strings"
+	"testing"
+)
+
+const testObj = `# A quad and a flat shaded triangle
+mtllib scene.mtl
+
+v -1 -1 0
+v 1 -1 0
+v 1 1 0
+v -1 1 0
+v 0 0 2 1 0 0
+vt 0 0
+vt 1 0
+vt 1 1
+vt 0 1
+vn 0 0 2
+
+usemtl crate
+f 1/1/1 2/2/1 3/3/1 4/4/1
+
+usemtl red
+f -5 -4 -1
+
+usemtl crate
+f 1/1/1 3/3/1 4/4/1
+`
+
+const testMtl = `newmtl crate
+Kd 1 1 1
+map_Kd -s 1 1 1 textures/crate.png
+
+newmtl red
+Kd 1 0 0
+Tr 0.25
+`
+
+func TestDecodeOBJ(t *testing.T) {
+	obj, err := DecodeOBJ(strings.NewReader(testObj))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(obj.MaterialLibs) != 1 || obj.MaterialLibs[0] != "scene.mtl" {
+		t.Fatalf("Unexpected material libs: %v", obj.MaterialLibs)
+	}
+	if len(obj.Groups) != 2 || obj.Groups[0].Material != "crate" || obj.Groups[1].Material != "red" {
+		t.Fatalf("Unexpected groups: %v", obj.Groups)
+	}
+
+	// Both crate faces share the same 4 vertices
+	crate := obj.Groups[0].Mesh
+	if crate.VertexCount() != 4 || len(crate.Indices()) != 9 {
+		t.Fatalf("Unexpected crate size: %d vertices, %d indices", crate.VertexCount(), len(crate.Indices()))
+	}
+	for i := 0; i < crate.VertexCount(); i++ {
+		v := crate.Vertex(i)
+		if v.Normal != (Vec3{0, 0, 1}) {
+			t.Fatalf("Normals should be normalized: %v", v.Normal)
+		}
+		// The top of the quad is the top of the texture
+		if v.Position[1] > 0 && v.TexCoord[1] != 0 {
+			t.Fatalf("Texture coordinates should be flipped: %v", v)
+		}
+	}
+	if crate.Bounds() != (Box{Vec3{-1, -1, 0}, Vec3{1, 1, 0}}) {
+		t.Fatalf("Unexpected bounds: %v", crate.Bounds())
+	}
+	if len(crate.Tangents()) != 4 || crate.Tangents()[0] != (Vec4{1, 0, 0, -1}) {
+		t.Fatalf("Unexpected tangents: %v", crate.Tangents())
+	}
+
+	// Relative indices, a vertex color and a flat normal
+	red := obj.Groups[1].Mesh
+	if red.VertexCount() != 3 {
+		t.Fatalf("Unexpected red size: %d vertices", red.VertexCount())
+	}
+	inds := red.Indices()
+	pos := red.Positions()
+	face := pos[inds[1]].Sub(pos[inds[0]]).Cross(pos[inds[2]].Sub(pos[inds[0]])).Unit()
+	for i := 0; i < 3; i++ {
+		v := red.Vertex(i)
+		if v.Normal.Sub(face).Len() > 1e-5 {
+			t.Fatalf("Expected the face normal %v, got %v", face, v.Normal)
+		}
+		if v.Position == (Vec3{0, 0, 2}) && v.Color != (RGBA{1, 0, 0, 1}) {
+			t.Fatalf("Unexpected vertex color: %v", v.Color)
+		}
+	}
+}
+
+func TestDecodeOBJErrors(t *testing.T) {
+	bad := []string{
+		"v 0 0 0\nv 1 0 0\nf 1 2 3\n", // Index out of range
+		"v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2\n", // Not enough corners
+		"v 0 zero 0\n",
+		"vn 0 0\n",
+	}
+	for _, data := range bad {
+		if _, err := DecodeOBJ(strings.NewReader(data)); err == nil {
+			t.Fatalf("Expected an error for %q", data)
+		}
+	}
+}
+
+func TestDecodeMTL(t *testing.T) {
+	materials, err := DecodeMTL(strings.NewReader(testMtl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(materials) != 2 {
+		t.Fatalf("Unexpected materials: %v", materials)
+	}
+	if materials[0].Name != "crate" || materials[0].DiffuseMap != "textures/crate.png" || materials[0].Diffuse != White {
+		t.Fatalf("Unexpected crate material: %v", materials[0])
+	}
+	if materials[1].Name != "red" || materials[1].DiffuseMap != "" || materials[1].Diffuse != (RGBA{1, 0, 0, 0.75}) {
+		t.Fatalf("Unexpected red material: %v", materials[1])
+	}
+}
@@ -0,0 +1,214 @@
+package glitch
+
+import (
+	"fmt"
+	"image"
+	"sort"
+)
+
+// Packs many images into one or more shared texture pages so that the resulting sprites all share a material (and therefore batch together)
+type AtlasPacker struct {
+	pages []*atlasPage
+	size int // The width and height of each page
+	padding int // The number of empty pixels left between packed images
+	smooth bool
+}
+
+type atlasPage struct {
+	texture *Texture
+	material SpriteMaterial
+	skyline *skyline
+}
+
+// Creates an atlas packer whose pages are size x size pixels. Padding is the number of empty pixels placed between each packed image
+func NewAtlasPacker(size int, padding int, smooth bool) *AtlasPacker {
+	return &AtlasPacker{
+		pages: make([]*atlasPage, 0),
+		size: size,
+		padding: padding,
+		smooth: smooth,
+	}
+}
+
+func (p *AtlasPacker) newPage() *atlasPage {
+	img := image.NewRGBA(image.Rect(0, 0, p.size, p.size))
+	texture := NewTexture(img, p.smooth)
+	page := &atlasPage{
+		texture: texture,
+		material: NewSpriteMaterial(texture),
+		skyline: newSkyline(p.size, p.size),
+	}
+	p.pages = append(p.pages, page)
+	return page
+}
+
+// Packs the image into the first page that has room for it (adding a new page if none do), uploads it, and returns a sprite for it
+func (p *AtlasPacker) Add(img image.Image) (*Sprite, error) {
+	w := img.Bounds().Dx()
+	h := img.Bounds().Dy()
+	if w + p.padding > p.size || h + p.padding > p.size {
+		return nil, fmt.Errorf("AtlasPacker: image (%d x %d) does not fit in page size %d", w, h, p.size)
+	}
+
+	for _, page := range p.pages {
+		x, y, ok := page.skyline.Insert(w + p.padding, h + p.padding)
+		if ok {
+			return page.upload(img, x, y), nil
+		}
+	}
+
+	// The size check above guarantees that the image fits in an empty page
+	page := p.newPage()
+	x, y, _ := page.skyline.Insert(w + p.padding, h + p.padding)
+	return page.upload(img, x, y), nil
+}
+
+// Packs all of the images and returns their sprites in the same order as the images were passed in.
+// Images are packed tallest first, which generally packs tighter than packing in the order received
+func (p *AtlasPacker) AddAll(imgs []image.Image) ([]*Sprite, error) {
+	order := make([]int, len(imgs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return imgs[order[i]].Bounds().Dy() > imgs[order[j]].Bounds().Dy()
+	})
+
+	sprites := make([]*Sprite, len(imgs))
+	for _, idx := range order {
+		sprite, err := p.Add(imgs[idx])
+		if err != nil {
+			return nil, err
+		}
+		sprites[idx] = sprite
+	}
+	return sprites, nil
+}
+
+// Returns the textures of every page that has been allocated so far
+func (p *AtlasPacker) Pages() []*Texture {
+	textures := make([]*Texture, len(p.pages))
+	for i := range p.pages {
+		textures[i] = p.pages[i].texture
+	}
+	return textures
+}
+
+func (page *atlasPage) upload(img image.Image, x, y int) *Sprite {
+	rgba := toRgba(img)
+	w := rgba.Bounds().Dx()
+	h := rgba.Bounds().Dy()
+	if w > 0 && h > 0 {
+		// Empty images (Ex: blank glyphs or fully trimmed sprites) have no pixels to upload
+		page.texture.SetPixels(x, y, w, h, rgba.Pix)
+	}
+
+	bounds := R(float32(x), float32(y), float32(x + w), float32(y + h))
+	sprite := NewSprite(page.texture, bounds)
+	sprite.material = page.material // All sprites on a page share the page's material
+	return sprite
+}
+
+// A bottom-left skyline rectangle packer
+// https://jvernay.fr/en/blog/skyline-2d-packer/implementation/
+type skyline struct {
+	width, height int
+	nodes []skylineNode
+}
+
+// A horizontal segment of the skyline, starting at x and spanning w pixels, whose top is at y
+type skylineNode struct {
+	x, y, w int
+}
+
+func newSkyline(width, height int) *skyline {
+	return &skyline{
+		width: width,
+		height: height,
+		nodes: []skylineNode{ {0, 0, width} },
+	}
+}
+
+// Finds a spot for a w x h rect, reserves it and returns its top left corner. Returns false if there was no room
+func (s *skyline) Insert(w, h int) (int, int, bool) {
+	bestIdx := -1
+	bestX, bestY := 0, 0
+	bestWidth := 0
+	for i := range s.nodes {
+		y, ok := s.fit(i, w, h)
+		if !ok { continue }
+
+		// Prefer the lowest spot, then the narrowest segment to reduce wasted space
+		if bestIdx < 0 || y < bestY || (y == bestY && s.nodes[i].w < bestWidth) {
+			bestIdx = i
+			bestX = s.nodes[i].x
+			bestY = y
+			bestWidth = s.nodes[i].w
+		}
+	}
+
+	if bestIdx < 0 {
+		return 0, 0, false
+	}
+
+	s.add(bestIdx, bestX, bestY, w, h)
+	return bestX, bestY, true
+}
+
+// Returns the y position that a w x h rect would sit at if it's left edge was placed at node idx
+func (s *skyline) fit(idx int, w, h int) (int, bool) {
+	x := s.nodes[idx].x
+	if x + w > s.width {
+		return 0, false
+	}
+
+	y := 0
+	remaining := w
+	for i := idx; remaining > 0; i++ {
+		if i >= len(s.nodes) {
+			return 0, false
+		}
+		if s.nodes[i].y > y {
+			y = s.nodes[i].y
+		}
+		remaining -= s.nodes[i].w
+	}
+
+	if y + h > s.height {
+		return 0, false
+	}
+	return y, true
+}
+
+func (s *skyline) add(idx int, x, y, w, h int) {
+	node := skylineNode{x, y + h, w}
+	s.nodes = append(s.nodes, skylineNode{})
+	copy(s.nodes[idx+1:], s.nodes[idx:])
+	s.nodes[idx] = node
+
+	// Shrink or remove the nodes that are now covered by the new node
+	for i := idx + 1; i < len(s.nodes); i++ {
+		prevEnd := s.nodes[i-1].x + s.nodes[i-1].w
+		if s.nodes[i].x >= prevEnd {
+			break
+		}
+
+		shrink := prevEnd - s.nodes[i].x
+		s.nodes[i].x += shrink
+		s.nodes[i].w -= shrink
+		if s.nodes[i].w > 0 {
+			break
+		}
+		s.nodes = append(s.nodes[:i], s.nodes[i+1:]...)
+		i--
+	}
+
+	// Merge neighbouring nodes that are at the same height
+	for i := 0; i < len(s.nodes)-1; i++ {
+		if s.nodes[i].y == s.nodes[i+1].y {
+			s.nodes[i].w += s.nodes[i+1].w
+			s.nodes = append(s.nodes[:i+1], s.nodes[i+2:]...)
+			i--
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package glitch
+
+import (
+	"image"
+	"testing"
+)
+
+func TestSkylineNoOverlap(t *testing.T) {
+	s := newSkyline(128, 128)
+
+	sizes := [][2]int{
+		{32, 16}, {16, 32}, {64, 8}, {10, 10}, {50, 20},
+		{8, 64}, {30, 30}, {12, 5}, {40, 12}, {20, 20},
+	}
+
+	rects := make([]image.Rectangle, 0)
+	for _, size := range sizes {
+		x, y, ok := s.Insert(size[0], size[1])
+		if !ok {
+			t.Fatalf("Failed to insert %v", size)
+		}
+		r := image.Rect(x, y, x + size[0], y + size[1])
+		if !r.In(image.Rect(0, 0, 128, 128)) {
+			t.Fatalf("Rect out of bounds: %v", r)
+		}
+		for _, o := range rects {
+			if r.Overlaps(o) {
+				t.Fatalf("Rect %v overlaps %v", r, o)
+			}
+		}
+		rects = append(rects, r)
+	}
+}
+
+func TestSkylineFull(t *testing.T) {
+	s := newSkyline(64, 64)
+	for i := 0; i < 16; i++ {
+		_, _, ok := s.Insert(16, 16)
+		if !ok {
+			t.Fatalf("Failed to insert rect %d", i)
+		}
+	}
+
+	_, _, ok := s.Insert(1, 1)
+	if ok {
+		t.Fatalf("Inserted into a full skyline")
+	}
+}
+
+func TestAtlasPageUploadEmpty(t *testing.T) {
+	// The texture is never created on the GPU, so this would fail if the empty image was uploaded
+	texture := &Texture{width: 64, height: 64}
+	page := &atlasPage{
+		texture: texture,
+		material: NewSpriteMaterial(texture),
+		skyline: newSkyline(64, 64),
+	}
+
+	for _, rect := range []image.Rectangle{image.Rect(0, 0, 0, 0), image.Rect(0, 0, 8, 0), image.Rect(0, 0, 0, 8)} {
+		sprite := page.upload(image.NewRGBA(rect), 4, 4)
+		if sprite.Bounds().W() != float32(rect.Dx()) || sprite.Bounds().H() != float32(rect.Dy()) {
+			t.Fatalf("Wrong sprite bounds: %v", sprite.Bounds())
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package glitch
+
+// The texture unit that PaletteMaterial binds its palette to. Set the shader's "palette" sampler uniform to this (Ex: pass.SetUniform("palette", int32(PaletteTextureUnit)))
+const PaletteTextureUnit = 1
+
+// A material for palette indexed sprites (See shaders.PaletteShader).
+// The red channel of the index texture selects a column of the palette texture, and the color mask selects a row (See PaletteMask). Each row of the palette is one variant of the sprite, so variants that share a palette still batch together.
+// Note: Both textures should use nearest filtering
+type PaletteMaterial struct {
+	index *Texture
+	palette *Texture
+}
+
+func NewPaletteMaterial(index, palette *Texture) PaletteMaterial {
+	return PaletteMaterial{
+		index: index,
+		palette: palette,
+	}
+}
+
+func (m PaletteMaterial) Bind() {
+	m.index.Bind(0)
+	m.palette.Bind(PaletteTextureUnit)
+}
+
+// Creates a sprite out of a region of an index texture, which gets its colors from the palette
+func NewPaletteSprite(index, palette *Texture, bounds Rect) *Sprite {
+	sprite := NewSprite(index, bounds)
+	sprite.material = NewPaletteMaterial(index, palette)
+	return sprite
+}
+
+// Returns the color mask which draws a palette sprite with the colors of a palette row (0 is the top row).
+// The row is stored in the red channel, so palettes can have at most 256 rows. Palette sprites can't be tinted by the mask, so tints should be added as extra rows
+func PaletteMask(row int, alpha float32) RGBA {
+	return RGBA{float32(row) / 255.0, 1.0, 1.0, alpha}
+}
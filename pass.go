@@ -41,6 +41,15 @@ type RenderPass struct {
 	dirty bool // Indicates if we need to re-draw to the buffers
 	DepthTest bool // If set true, enable hardware depth testing
 	SoftwareSort SoftwareSortMode
+
+	cull *Frustum
+	stats PassStats
+}
+
+// Counts the commands added to a RenderPass since it was last cleared
+type PassStats struct {
+	Commands int // The number of commands that will be drawn
+	Culled int // The number of commands that were skipped because they were outside of the cull frustum
 }
 
 type SoftwareSortMode uint8
@@ -69,6 +78,7 @@ func NewRenderPass(shader *Shader) *RenderPass {
 
 func (r *RenderPass) Clear() {
 	r.dirty = true
+	r.stats = PassStats{}
 	// Clear stuff
 	r.buffer.Clear()
 	// r.commands = r.commands[:0]
@@ -160,6 +170,16 @@ func (r *RenderPass) Draw(target Target) {
 							normBuf[i] = vec
 						}
 
+					case TangentXYZW:
+						// Tangents are directions, so they are moved by the matrix without its translation
+						origin := c.matrix.Apply(Vec3{0, 0, 0})
+						tanBuf := *(destBuffs[bufIdx]).(*[]Vec4)
+						for i := range c.mesh.tangents {
+							t := c.mesh.tangents[i]
+							vec := c.matrix.Apply(Vec3{t[0], t[1], t[2]}).Sub(origin)
+							tanBuf[i] = Vec4{vec[0], vec[1], vec[2], t[3]}
+						}
+
 						// Colors
 					case ColorR:
 						colBuf := *(destBuffs[bufIdx]).(*[]float32)
@@ -251,12 +271,29 @@ func (r *RenderPass) SetUniform(name string, value interface{}) {
 // Option 2: I could also just offset the geometry when I create the sprite (or after). Then simply use the transforms like normal. I'd just have to offset the sprite by the height, and then not add the height to the Y transformation
 // Option 3: I can batch together these sprites into a single thing that is then rendered
 func (r *RenderPass) Add(mesh *Mesh, mat Mat4, mask RGBA, material Material) {
+	if r.cull != nil && mesh != nil && r.cull.culls(mesh, mat) {
+		r.stats.Culled++
+		return
+	}
+	r.stats.Commands++
+
 	r.dirty = true
 	r.commands[r.currentLayer] = append(r.commands[r.currentLayer], drawCommand{
 		0, mesh, mat, mask, material,
 	})
 }
 
+// Skips any commands added after this whose transformed mesh bounds are outside of the frustum. Pass nil to stop culling.
+// The frustum usually comes from the camera the pass is drawn with (Ex: CameraOrtho.Frustum), so set it again when the camera moves
+func (r *RenderPass) SetCulling(frustum *Frustum) {
+	r.cull = frustum
+}
+
+// Returns how many commands were drawn and culled since the last Clear
+func (r *RenderPass) Stats() PassStats {
+	return r.stats
+}
+
 func (r *RenderPass) SortInSoftware() {
 	if r.SoftwareSort == SoftwareSortNone { return } // Skip if sorting disabled
 
@@ -0,0 +1,314 @@
+package glitch
+
+import (
+	"math"
+)
+
+// The default distance that flattened curves are allowed to stray from the true curve
+const DefaultTolerance = 0.25
+
+// Builds 2D vector shapes out of lines and curves, similar to the html canvas path API. Draw them with GeomDraw.FillPath and GeomDraw.StrokePath.
+// Curves are flattened into line segments as they are added, using just enough segments to stay within Tolerance of the curve
+type Path struct {
+	Tolerance float32
+	contours []pathContour
+	current Vec2
+	start Vec2 // The start of the current contour, which Close returns to
+	open bool // True if the next segment continues the last contour
+	hasCurrent bool // False until the first point is added, so the first segment doesn't start from the origin
+}
+
+type pathContour struct {
+	points []Vec2
+	closed bool
+}
+
+func NewPath() *Path {
+	return &Path{
+		Tolerance: DefaultTolerance,
+		contours: make([]pathContour, 0),
+	}
+}
+
+// Removes everything from the path
+func (p *Path) Clear() {
+	p.contours = p.contours[:0]
+	p.current = Vec2{}
+	p.start = Vec2{}
+	p.open = false
+	p.hasCurrent = false
+}
+
+// Starts a new contour at the point
+func (p *Path) MoveTo(point Vec2) *Path {
+	p.current = point
+	p.start = point
+	p.open = false
+	p.hasCurrent = true
+	return p
+}
+
+func (p *Path) LineTo(point Vec2) *Path {
+	p.lineTo(point)
+	return p
+}
+
+// Draws a quadratic bezier curve from the current point to the point
+func (p *Path) QuadTo(control, point Vec2) *Path {
+	if !p.hasCurrent {
+		p.MoveTo(control)
+	}
+	p0 := p.current
+	// The curve is within |p0 - 2c + p1| / (4 * n^2) of n uniformly spaced chords
+	dd := p0.Sub(control.Scaled(2)).Add(point).Len()
+	n := p.segments(math.Sqrt(float64(dd) / (4 * float64(p.tolerance()))))
+	for i := 1; i <= n; i++ {
+		t := float32(i) / float32(n)
+		mt := 1 - t
+		p.lineTo(p0.Scaled(mt * mt).Add(control.Scaled(2 * mt * t)).Add(point.Scaled(t * t)))
+	}
+	return p
+}
+
+// Draws a cubic bezier curve from the current point to the point
+func (p *Path) CubicTo(control1, control2, point Vec2) *Path {
+	if !p.hasCurrent {
+		p.MoveTo(control1)
+	}
+	p0 := p.current
+	// The curve is within 6 * max(|p0 - 2c1 + c2|, |c1 - 2c2 + p1|) / (8 * n^2) of n uniformly spaced chords
+	dd1 := p0.Sub(control1.Scaled(2)).Add(control2).Len()
+	dd2 := control1.Sub(control2.Scaled(2)).Add(point).Len()
+	dd := math.Max(float64(dd1), float64(dd2))
+	n := p.segments(math.Sqrt((3 * dd) / (4 * float64(p.tolerance()))))
+	for i := 1; i <= n; i++ {
+		t := float32(i) / float32(n)
+		mt := 1 - t
+		p.lineTo(p0.Scaled(mt * mt * mt).
+			Add(control1.Scaled(3 * mt * mt * t)).
+			Add(control2.Scaled(3 * mt * t * t)).
+			Add(point.Scaled(t * t * t)))
+	}
+	return p
+}
+
+// Draws a circular arc around the center, starting at the angle and sweeping counter clockwise by sweep radians (negative sweeps go clockwise).
+// A line is drawn from the current point to the start of the arc
+func (p *Path) Arc(center Vec2, radius, start, sweep float32) *Path {
+	p.arc(center, radius, start, sweep)
+	return p
+}
+
+// Draws a line towards corner and then an arc which curves around to head towards point, like the canvas arcTo.
+// The arc is tangent to both lines and ends on the line between corner and point. If the lines don't form a corner, this is just LineTo(corner)
+func (p *Path) ArcTo(corner, point Vec2, radius float32) *Path {
+	if !p.hasCurrent {
+		p.MoveTo(corner)
+	}
+	v1 := p.current.Sub(corner)
+	v2 := point.Sub(corner)
+	l1 := v1.Len()
+	l2 := v2.Len()
+	if radius <= 0 || l1 == 0 || l2 == 0 {
+		p.lineTo(corner)
+		return p
+	}
+	v1 = v1.Scaled(1 / l1)
+	v2 = v2.Scaled(1 / l2)
+
+	cross := (v1[0] * v2[1]) - (v1[1] * v2[0])
+	if math.Abs(float64(cross)) < 1e-6 {
+		p.lineTo(corner) // Collinear
+		return p
+	}
+
+	// The angle between the two lines at the corner
+	cosPhi := float64((v1[0] * v2[0]) + (v1[1] * v2[1]))
+	phi := math.Acos(math.Max(-1, math.Min(1, cosPhi)))
+
+	tangentDist := float32(float64(radius) / math.Tan(phi / 2))
+	centerDist := float32(float64(radius) / math.Sin(phi / 2))
+	bisector := v1.Add(v2)
+	bisector = bisector.Scaled(1 / bisector.Len())
+
+	t1 := corner.Add(v1.Scaled(tangentDist))
+	t2 := corner.Add(v2.Scaled(tangentDist))
+	center := corner.Add(bisector.Scaled(centerDist))
+
+	start := math.Atan2(float64(t1[1] - center[1]), float64(t1[0] - center[0]))
+	end := math.Atan2(float64(t2[1] - center[1]), float64(t2[0] - center[0]))
+	sweep := end - start
+	for sweep > math.Pi {
+		sweep -= 2 * math.Pi
+	}
+	for sweep < -math.Pi {
+		sweep += 2 * math.Pi
+	}
+
+	p.arc(center, radius, float32(start), float32(sweep))
+	return p
+}
+
+// Closes the current contour with a line back to its start
+func (p *Path) Close() *Path {
+	if p.open {
+		p.contours[len(p.contours)-1].closed = true
+	}
+	p.current = p.start
+	p.open = false
+	return p
+}
+
+func (p *Path) tolerance() float32 {
+	if p.Tolerance <= 0 {
+		return DefaultTolerance
+	}
+	return p.Tolerance
+}
+
+// Returns the number of segments to flatten a curve into
+func (p *Path) segments(n float64) int {
+	if n < 1 || math.IsNaN(n) {
+		return 1
+	}
+	return int(math.Ceil(math.Min(n, 10000)))
+}
+
+func (p *Path) lineTo(point Vec2) {
+	if !p.hasCurrent {
+		p.MoveTo(point)
+		return
+	}
+	if !p.open {
+		p.contours = append(p.contours, pathContour{
+			points: []Vec2{p.current},
+		})
+		p.open = true
+	}
+	c := &p.contours[len(p.contours)-1]
+	if c.points[len(c.points)-1] != point {
+		c.points = append(c.points, point)
+	}
+	p.current = point
+}
+
+func (p *Path) arc(center Vec2, radius, start, sweep float32) {
+	if radius <= 0 { return }
+
+	// Each chord spanning an angle of a strays r * (1 - cos(a / 2)) from the arc
+	n := 1
+	tolerance := p.tolerance()
+	if tolerance < radius {
+		step := 2 * math.Acos(1 - float64(tolerance / radius))
+		n = p.segments(math.Abs(float64(sweep)) / step)
+	}
+
+	for i := 0; i <= n; i++ {
+		angle := float64(start + (sweep * float32(i) / float32(n)))
+		p.lineTo(center.Add(Vec2{float32(math.Cos(angle)), float32(math.Sin(angle))}.Scaled(radius)))
+	}
+}
+
+// Returns the flattened points of every contour that has at least the minimum number of points
+func (p *Path) contourPoints(min int) ([][]Vec3, []bool) {
+	points := make([][]Vec3, 0, len(p.contours))
+	closed := make([]bool, 0, len(p.contours))
+	for _, c := range p.contours {
+		cPoints := c.points
+		cClosed := c.closed
+		if len(cPoints) > 1 && cPoints[0] == cPoints[len(cPoints)-1] {
+			// The contour came back around to its start (Ex: a full circle)
+			cPoints = cPoints[:len(cPoints)-1]
+			cClosed = true
+		}
+		if len(cPoints) < min { continue }
+
+		p3 := make([]Vec3, len(cPoints))
+		for i := range cPoints {
+			p3[i] = cPoints[i].Vec3()
+		}
+		points = append(points, p3)
+		closed = append(closed, cClosed)
+	}
+	return points, closed
+}
+
+// Returns true if the point is inside of the polygon
+func pointInPolygon(point Vec2, polygon []Vec3) bool {
+	inside := false
+	for i := range polygon {
+		a := polygon[i]
+		b := polygon[(i + 1) % len(polygon)]
+		if (a[1] > point[1]) == (b[1] > point[1]) { continue }
+		x := a[0] + ((point[1] - a[1]) * (b[0] - a[0]) / (b[1] - a[1]))
+		if point[0] < x {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Fills every contour of the path, closing any open ones. Contours which are nested inside of another contour alternate between being holes and being filled (The even-odd rule).
+// Contours are expected not to intersect each other or themselves
+func (g *GeomDraw) FillPath(path *Path) *Mesh {
+	contours, _ := path.contourPoints(3)
+
+	// The number of contours that each contour is inside of
+	depth := make([]int, len(contours))
+	parent := make([]int, len(contours))
+	for i := range contours {
+		parent[i] = -1
+		for j := range contours {
+			if i == j { continue }
+			if pointInPolygon(contours[i][0].Vec2(), contours[j]) {
+				depth[i]++
+			}
+		}
+	}
+	// A hole belongs to the deepest contour which contains it
+	for i := range contours {
+		if depth[i] % 2 == 0 { continue }
+		for j := range contours {
+			if i == j || depth[j] != depth[i] - 1 { continue }
+			if pointInPolygon(contours[i][0].Vec2(), contours[j]) {
+				parent[i] = j
+				break
+			}
+		}
+	}
+
+	positions := make([]Vec3, 0)
+	inds := make([]uint32, 0)
+	for i := range contours {
+		if depth[i] % 2 == 1 { continue }
+
+		holes := make([][]Vec3, 0)
+		for j := range contours {
+			if parent[j] == i {
+				holes = append(holes, contours[j])
+			}
+		}
+
+		start := uint32(len(positions))
+		positions = append(positions, contours[i]...)
+		for _, h := range holes {
+			positions = append(positions, h...)
+		}
+		for _, idx := range Triangulate(contours[i], holes...) {
+			inds = append(inds, start + idx)
+		}
+	}
+
+	return g.fillMesh(positions, inds)
+}
+
+// Strokes every contour of the path (See Stroke)
+func (g *GeomDraw) StrokePath(path *Path, width float32) *Mesh {
+	contours, closed := path.contourPoints(2)
+
+	m := NewMesh()
+	for i := range contours {
+		m.Append(g.Stroke(contours[i], width, closed[i]))
+	}
+	return m
+}
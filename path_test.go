@@ -0,0 +1,61 @@
+package glitch
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPathFlattenTolerance(t *testing.T) {
+	path := NewPath()
+	path.Tolerance = 0.1
+	path.MoveTo(Vec2{0, 0}).QuadTo(Vec2{50, 100}, Vec2{100, 0})
+
+	points := path.contours[0].points
+	if len(points) < 3 {
+		t.Fatalf("Curve wasn't flattened: %v", points)
+	}
+	// The middle of every chord should be close to the curve, which is y = 2x - x^2/50
+	for i := 0; i < len(points) - 1; i++ {
+		mid := points[i].Add(points[i+1]).Scaled(0.5)
+		curveY := (2 * mid[0]) - (mid[0] * mid[0] / 50)
+		if math.Abs(float64(curveY - mid[1])) > 0.1 {
+			t.Fatalf("Chord strays %v from the curve", curveY - mid[1])
+		}
+	}
+
+	// A coarser tolerance uses fewer points
+	coarse := NewPath()
+	coarse.Tolerance = 2
+	coarse.MoveTo(Vec2{0, 0}).QuadTo(Vec2{50, 100}, Vec2{100, 0})
+	if len(coarse.contours[0].points) >= len(points) {
+		t.Fatalf("Expected fewer points: %d >= %d", len(coarse.contours[0].points), len(points))
+	}
+}
+
+func TestFillPathHole(t *testing.T) {
+	path := NewPath()
+	path.Tolerance = 0.001
+	path.MoveTo(Vec2{0, 0}).LineTo(Vec2{10, 0}).LineTo(Vec2{10, 10}).LineTo(Vec2{0, 10}).Close()
+	path.MoveTo(Vec2{7, 5}).Arc(Vec2{5, 5}, 2, 0, 2 * math.Pi)
+
+	mesh := NewGeomDraw().FillPath(path)
+	want := 100 - (math.Pi * 4)
+	if math.Abs(float64(triangleArea(mesh.positions, mesh.indices)) - want) > 0.05 {
+		t.Fatalf("Area: got %v, want %v", triangleArea(mesh.positions, mesh.indices), want)
+	}
+	if mesh.Bounds().Max != (Vec3{10, 10, 0}) {
+		t.Fatalf("Unexpected bounds: %v", mesh.Bounds())
+	}
+}
+
+func TestArcTo(t *testing.T) {
+	path := NewPath()
+	path.MoveTo(Vec2{0, 0}).ArcTo(Vec2{10, 0}, Vec2{10, 10}, 2)
+
+	points := path.contours[0].points
+	first := points[1]
+	last := points[len(points)-1]
+	if first.Sub(Vec2{8, 0}).Len() > 0.001 || last.Sub(Vec2{10, 2}).Len() > 0.001 {
+		t.Fatalf("Arc should run from (8, 0) to (10, 2): %v", points)
+	}
+}
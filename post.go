@@ -0,0 +1,174 @@
+package glitch
+
+import (
+	"fmt"
+)
+
+// Texture units used by post effects
+const (
+	postUnitInput = 0 // The output of the previous effect ("texture1")
+	postUnitOriginal = 1 // The chain's input, before any effects ("original")
+	postUnitExtra = 2 // Textures added with PostEffect.SetTexture
+)
+
+// A fullscreen quad in clip space. UVs are flipped because frames render bottom-up
+var postQuad = NewQuadMesh(R(-1, -1, 1, 1), R(0, 1, 1, 0))
+
+// Binds each texture to the texture unit of its index
+type postMaterial struct {
+	textures []*Texture
+}
+
+func (m *postMaterial) Bind() {
+	for i, t := range m.textures {
+		if t == nil { continue }
+		t.Bind(i)
+	}
+}
+
+// A fullscreen fragment shader pass in a PostChain.
+// The shader's vertex format should only contain PositionXY and TexCoordXY attributes (See shaders.PostVertexShader) and the previous effect's output is sampled through "texture1".
+// If they are in the shader's uniform format, these uniforms are set automatically:
+//   - "original" (sampler2D): The chain's input frame, before any effects were applied
+//   - "texelSize" (vec2): The size of one pixel of the input, in texture coordinates
+type PostEffect struct {
+	shader *Shader
+	pass *RenderPass
+	material *postMaterial
+	samplers []string // Samplers of the extra textures, in the order of their texture units
+	extra []*Texture
+}
+
+func NewPostEffect(shader *Shader) *PostEffect {
+	return &PostEffect{
+		shader: shader,
+		pass: NewRenderPass(shader),
+		material: &postMaterial{},
+	}
+}
+
+// Sets a uniform which is applied every time the effect is drawn
+func (e *PostEffect) SetUniform(name string, value interface{}) {
+	e.pass.SetUniform(name, value)
+}
+
+// Binds an extra texture (such as a color grading LUT) to a sampler uniform
+func (e *PostEffect) SetTexture(sampler string, texture *Texture) {
+	for i := range e.samplers {
+		if e.samplers[i] == sampler {
+			e.extra[i] = texture
+			return
+		}
+	}
+	e.samplers = append(e.samplers, sampler)
+	e.extra = append(e.extra, texture)
+	e.pass.SetUniform(sampler, int32(postUnitExtra + len(e.extra) - 1))
+}
+
+// Draws the effect to the target, reading from input
+func (e *PostEffect) Draw(target Target, input, original *Texture) {
+	e.material.textures = append(e.material.textures[:0], input, original)
+	e.material.textures = append(e.material.textures, e.extra...)
+
+	if e.shader.hasUniform("original") {
+		e.pass.SetUniform("original", int32(postUnitOriginal))
+	}
+	if e.shader.hasUniform("texelSize") {
+		e.pass.SetUniform("texelSize", Vec2{1.0 / float32(input.width), 1.0 / float32(input.height)})
+	}
+
+	e.pass.Clear()
+	e.pass.Add(postQuad, Mat4Ident, RGBA{1.0, 1.0, 1.0, 1.0}, e.material)
+	e.pass.Draw(target)
+}
+
+// Applies an ordered list of post effects to whatever was drawn into its frame.
+// Effects ping pong between two intermediate frames, so the chain's input frame is left untouched for effects which need the original image (Ex: bloom)
+type PostChain struct {
+	input *Frame
+	frames [2]*Frame
+	effects []*PostEffect
+}
+
+// Creates a post chain. The input frame uses the full config, but the intermediate frames drop the depth, stencil and multisample options because effects are only drawn as fullscreen quads
+func NewPostChain(bounds Rect, config FrameConfig, effects ...*PostEffect) (*PostChain, error) {
+	input, err := NewFrameExt(bounds, config)
+	if err != nil {
+		return nil, fmt.Errorf("NewPostChain: %w", err)
+	}
+
+	chain := &PostChain{
+		input: input,
+		effects: effects,
+	}
+
+	pingPongConfig := FrameConfig{
+		Texture: config.Texture,
+	}
+	for i := range chain.frames {
+		chain.frames[i], err = NewFrameExt(bounds, pingPongConfig)
+		if err != nil {
+			return nil, fmt.Errorf("NewPostChain: %w", err)
+		}
+	}
+
+	return chain, nil
+}
+
+// Returns the frame that the scene should be drawn into
+func (c *PostChain) Frame() *Frame {
+	return c.input
+}
+
+func (c *PostChain) Bounds() Rect {
+	return c.input.Bounds()
+}
+
+func (c *PostChain) SetEffects(effects ...*PostEffect) {
+	c.effects = effects
+}
+
+func (c *PostChain) Effects() []*PostEffect {
+	return c.effects
+}
+
+// Resizes all of the chain's frames
+func (c *PostChain) Resize(bounds Rect) {
+	c.input.Resize(bounds)
+	for _, f := range c.frames {
+		f.Resize(bounds)
+	}
+}
+
+// Makes all of the chain's frames track the window's size. Pass nil to stop tracking
+func (c *PostChain) TrackWindow(win *Window) {
+	c.input.TrackWindow(win)
+	for _, f := range c.frames {
+		f.TrackWindow(win)
+	}
+}
+
+// Runs every effect in order and draws the last one to the target. The last effect is drawn on top of what is already in the target, so clear it first if needed.
+// If there are no effects, the input frame is blitted to the target, which returns an error where blits aren't supported (See Blit)
+func (c *PostChain) Draw(target Target) error {
+	if len(c.effects) <= 0 {
+		bounds := c.input.Bounds()
+		return Blit(c.input, target, bounds, bounds, FilterNearest)
+	}
+
+	original := c.input.Texture()
+	input := original
+	last := len(c.effects) - 1
+	for i, effect := range c.effects {
+		if i == last {
+			effect.Draw(target, input, original)
+			break
+		}
+
+		frame := c.frames[i % 2]
+		Clear(frame, RGBA{0, 0, 0, 0})
+		effect.Draw(frame, input, original)
+		input = frame.Texture()
+	}
+	return nil
+}
@@ -0,0 +1,403 @@
+package glitch
+
+import (
+	"math"
+)
+
+// Procedural 3D meshes. They are all centered on the origin with Z up, wound counter clockwise when viewed from outside, and colored white.
+// Texture coordinates have v = 0 at the top, like sprites, and every mesh has normals and tangents (See Mesh.RecalculateTangents)
+
+// The fewest segments and rings that still make a closed shape. Smaller values are clamped up to these
+const (
+	minPrimitiveSegments = 3
+	minPrimitiveRings = 2
+)
+
+func NewCubeMesh(size float32) *Mesh {
+	return NewBoxMesh(Vec3{size, size, size})
+}
+
+// Creates a box with the given width, depth and height. Each face maps the full texture, upright when viewed from outside with the top and bottom faces oriented towards +Y
+func NewBoxMesh(size Vec3) *Mesh {
+	half := size.Scaled(0.5, 0.5, 0.5)
+
+	// The right and up directions of each face, as seen from outside. right x up = normal
+	faces := []struct{
+		normal, right, up Vec3
+	}{
+		{Vec3{0, 0, 1}, Vec3{1, 0, 0}, Vec3{0, 1, 0}}, // Top
+		{Vec3{0, 0, -1}, Vec3{1, 0, 0}, Vec3{0, -1, 0}}, // Bottom
+		{Vec3{1, 0, 0}, Vec3{0, 1, 0}, Vec3{0, 0, 1}}, // Right
+		{Vec3{-1, 0, 0}, Vec3{0, -1, 0}, Vec3{0, 0, 1}}, // Left
+		{Vec3{0, 1, 0}, Vec3{-1, 0, 0}, Vec3{0, 0, 1}}, // Back
+		{Vec3{0, -1, 0}, Vec3{1, 0, 0}, Vec3{0, 0, 1}}, // Front
+	}
+
+	b := NewMeshBuilder()
+	for _, f := range faces {
+		corner := func(x, y float32, uv Vec2) uint32 {
+			p := f.normal.Add(f.right.Scaled(x, x, x)).Add(f.up.Scaled(y, y, y))
+			return b.AddVertex(Vertex{
+				Position: p.Scaled(half[0], half[1], half[2]),
+				Normal: f.normal,
+				Color: White,
+				TexCoord: uv,
+			})
+		}
+		b.AddQuad(
+			corner(-1, -1, Vec2{0, 1}),
+			corner(1, -1, Vec2{1, 1}),
+			corner(1, 1, Vec2{1, 0}),
+			corner(-1, 1, Vec2{0, 0}),
+		)
+	}
+	return buildPrimitive(b)
+}
+
+// Creates a flat square facing +Z (See NewGridMesh)
+func NewPlaneMesh(size Vec2) *Mesh {
+	return NewGridMesh(size, 1, 1)
+}
+
+// Creates a flat rectangle facing +Z, split into cols x rows quads. The texture is stretched across the whole grid
+func NewGridMesh(size Vec2, cols, rows int) *Mesh {
+	if cols < 1 { cols = 1 }
+	if rows < 1 { rows = 1 }
+
+	b := NewMeshBuilder()
+	for r := 0; r <= rows; r++ {
+		v := float32(r) / float32(rows)
+		for c := 0; c <= cols; c++ {
+			u := float32(c) / float32(cols)
+			b.AddVertex(Vertex{
+				Position: Vec3{(u - 0.5) * size[0], (0.5 - v) * size[1], 0},
+				Normal: Vec3{0, 0, 1},
+				Color: White,
+				TexCoord: Vec2{u, v},
+			})
+		}
+	}
+
+	rowLen := uint32(cols + 1)
+	for r := uint32(0); r < uint32(rows); r++ {
+		for c := uint32(0); c < uint32(cols); c++ {
+			topLeft := (r * rowLen) + c
+			bottomLeft := topLeft + rowLen
+			b.AddQuad(topLeft, bottomLeft, bottomLeft + 1, topLeft + 1)
+		}
+	}
+	return buildPrimitive(b)
+}
+
+// Creates a sphere out of rings of latitude and segments of longitude. The texture is wrapped around it with an equirectangular mapping, with the seam along +X
+func NewUVSphereMesh(radius float32, segments, rings int) *Mesh {
+	if rings < minPrimitiveRings { rings = minPrimitiveRings }
+
+	profile := make([]latheStep, 0, rings + 1)
+	for i := 0; i <= rings; i++ {
+		v := float32(i) / float32(rings)
+		sin, cos := sincos(math.Pi * float64(v))
+		if i == 0 || i == rings {
+			sin = 0 // Exactly, so that the poles are found
+		}
+		profile = append(profile, latheStep{
+			radius: radius * sin,
+			z: radius * cos,
+			normal: Vec2{sin, cos},
+			v: v,
+		})
+	}
+
+	b := NewMeshBuilder()
+	lathe(b, profile, segments)
+	return buildPrimitive(b)
+}
+
+// Creates a sphere by subdividing an icosahedron, which spreads the triangles more evenly than a UV sphere. Each subdivision makes 4 times as many triangles.
+// The texture is mapped like NewUVSphereMesh. Triangles that cross the seam have u values a bit above 1, so the texture should be set to repeat
+func NewIcoSphereMesh(radius float32, subdivisions int) *Mesh {
+	t := float32((1 + math.Sqrt(5)) / 2)
+	points := []Vec3{
+		{-1, t, 0}, {1, t, 0}, {-1, -t, 0}, {1, -t, 0},
+		{0, -1, t}, {0, 1, t}, {0, -1, -t}, {0, 1, -t},
+		{t, 0, -1}, {t, 0, 1}, {-t, 0, -1}, {-t, 0, 1},
+	}
+	for i := range points {
+		points[i] = points[i].Unit()
+	}
+	inds := []uint32{
+		0, 11, 5, 0, 5, 1, 0, 1, 7, 0, 7, 10, 0, 10, 11,
+		1, 5, 9, 5, 11, 4, 11, 10, 2, 10, 7, 6, 7, 1, 8,
+		3, 9, 4, 3, 4, 2, 3, 2, 6, 3, 6, 8, 3, 8, 9,
+		4, 9, 5, 2, 4, 11, 6, 2, 10, 8, 6, 7, 9, 8, 1,
+	}
+
+	for s := 0; s < subdivisions; s++ {
+		cache := make(map[[2]uint32]uint32)
+		midpoint := func(a, b uint32) uint32 {
+			if a > b {
+				a, b = b, a
+			}
+			key := [2]uint32{a, b}
+			if idx, ok := cache[key]; ok {
+				return idx
+			}
+			points = append(points, points[a].Add(points[b]).Unit())
+			idx := uint32(len(points) - 1)
+			cache[key] = idx
+			return idx
+		}
+
+		next := make([]uint32, 0, 4 * len(inds))
+		for i := 0; i + 2 < len(inds); i += 3 {
+			a, b, c := inds[i], inds[i+1], inds[i+2]
+			ab := midpoint(a, b)
+			bc := midpoint(b, c)
+			ca := midpoint(c, a)
+			next = append(next,
+				a, ab, ca,
+				ab, b, bc,
+				ca, bc, c,
+				ab, bc, ca,
+			)
+		}
+		inds = next
+	}
+
+	// Vertices aren't shared between triangles, so that triangles along the seam and at the poles can have their own texture coordinates
+	b := NewMeshBuilder()
+	for i := 0; i + 2 < len(inds); i += 3 {
+		tri := [3]Vec3{points[inds[i]], points[inds[i+1]], points[inds[i+2]]}
+		var uvs [3]Vec2
+		for j, p := range tri {
+			u := float32(math.Atan2(float64(p[1]), float64(p[0])) / (2 * math.Pi))
+			if u < 0 {
+				u += 1
+			}
+			z := math.Max(-1, math.Min(1, float64(p[2])))
+			v := float32(math.Acos(z) / math.Pi)
+			uvs[j] = Vec2{u, v}
+		}
+
+		// Triangles crossing the seam wrap around from 1 back to 0, so move the low side past 1
+		minU, maxU := uvs[0][0], uvs[0][0]
+		for _, uv := range uvs {
+			if uv[0] < minU { minU = uv[0] }
+			if uv[0] > maxU { maxU = uv[0] }
+		}
+		if maxU - minU > 0.5 {
+			for j := range uvs {
+				if uvs[j][0] < 0.5 {
+					uvs[j][0] += 1
+				}
+			}
+		}
+
+		// The poles have every u, so use the one in the middle of the triangle
+		for j, p := range tri {
+			if p[0] != 0 || p[1] != 0 { continue }
+			uvs[j][0] = (uvs[(j + 1) % 3][0] + uvs[(j + 2) % 3][0]) / 2
+		}
+
+		var vert [3]uint32
+		for j, p := range tri {
+			vert[j] = b.AddVertex(Vertex{
+				Position: p.Scaled(radius, radius, radius),
+				Normal: p,
+				Color: White,
+				TexCoord: uvs[j],
+			})
+		}
+		b.AddTriangle(vert[0], vert[1], vert[2])
+	}
+	return buildPrimitive(b)
+}
+
+// Creates a cylinder along the Z axis with capped ends. The texture wraps once around the side, and each cap maps a circle inscribed in the texture
+func NewCylinderMesh(radius, height float32, segments int) *Mesh {
+	half := height / 2
+	b := NewMeshBuilder()
+	lathe(b, []latheStep{
+		{radius: radius, z: half, normal: Vec2{1, 0}, v: 0},
+		{radius: radius, z: -half, normal: Vec2{1, 0}, v: 1},
+	}, segments)
+	addDisk(b, radius, half, segments, true)
+	addDisk(b, radius, -half, segments, false)
+	return buildPrimitive(b)
+}
+
+// Creates a cone along the Z axis, with the point at the top and a capped base. The texture is mapped like NewCylinderMesh
+func NewConeMesh(radius, height float32, segments int) *Mesh {
+	half := height / 2
+	slant := float32(math.Hypot(float64(radius), float64(height)))
+	normal := Vec2{1, 0}
+	if slant > 0 {
+		normal = Vec2{height / slant, radius / slant}
+	}
+
+	b := NewMeshBuilder()
+	lathe(b, []latheStep{
+		{radius: 0, z: half, normal: normal, v: 0},
+		{radius: radius, z: -half, normal: normal, v: 1},
+	}, segments)
+	addDisk(b, radius, -half, segments, false)
+	return buildPrimitive(b)
+}
+
+// Creates a capsule along the Z axis. The height is the total height including the rounded ends, and is raised to the diameter if it's smaller.
+// Rings is the number of rings in each rounded end. The texture wraps around like NewUVSphereMesh, with v spread evenly along the length of the surface
+func NewCapsuleMesh(radius, height float32, segments, rings int) *Mesh {
+	if rings < 1 { rings = 1 }
+	straight := height - (2 * radius)
+	if straight < 0 {
+		straight = 0
+	}
+	half := straight / 2
+	arcLength := math.Pi * float64(radius) / 2 // The length of each rounded end, from pole to equator
+	totalLength := (2 * arcLength) + float64(straight)
+
+	profile := make([]latheStep, 0, (2 * rings) + 2)
+	addRing := func(theta float64, z, distance float32) {
+		sin, cos := sincos(theta)
+		if theta == 0 || theta == math.Pi {
+			sin = 0 // Exactly, so that the poles are found
+		}
+		v := float32(0)
+		if totalLength > 0 {
+			v = distance / float32(totalLength)
+		}
+		profile = append(profile, latheStep{
+			radius: radius * sin,
+			z: z + (radius * cos),
+			normal: Vec2{sin, cos},
+			v: v,
+		})
+	}
+
+	for i := 0; i <= rings; i++ {
+		f := float64(i) / float64(rings)
+		addRing(f * math.Pi / 2, half, float32(f * arcLength))
+	}
+	start := 0
+	if straight == 0 {
+		start = 1 // The ends meet at the equator, so they share it
+	}
+	for i := start; i <= rings; i++ {
+		f := float64(i) / float64(rings)
+		addRing((1 + f) * math.Pi / 2, -half, float32(arcLength + float64(straight) + (f * arcLength)))
+	}
+
+	b := NewMeshBuilder()
+	lathe(b, profile, segments)
+	return buildPrimitive(b)
+}
+
+// Creates a torus around the Z axis. The major radius is from the center to the middle of the tube, and the minor radius is the radius of the tube.
+// The texture wraps once around the torus in u, and once around the tube in v, starting from the top of the tube
+func NewTorusMesh(majorRadius, minorRadius float32, majorSegments, minorSegments int) *Mesh {
+	if minorSegments < minPrimitiveSegments { minorSegments = minPrimitiveSegments }
+
+	// Going outwards from the top of the tube keeps the winding facing out
+	profile := make([]latheStep, 0, minorSegments + 1)
+	for i := 0; i <= minorSegments; i++ {
+		v := float32(i) / float32(minorSegments)
+		sin, cos := sincos((math.Pi / 2) - (2 * math.Pi * float64(v)))
+		profile = append(profile, latheStep{
+			radius: majorRadius + (minorRadius * cos),
+			z: minorRadius * sin,
+			normal: Vec2{cos, sin},
+			v: v,
+		})
+	}
+
+	b := NewMeshBuilder()
+	lathe(b, profile, majorSegments)
+	return buildPrimitive(b)
+}
+
+// One ring of a surface of revolution
+type latheStep struct {
+	radius, z float32
+	normal Vec2 // The normal in the plane of the profile, as (outwards, up)
+	v float32
+}
+
+// Spins the profile around the Z axis, adding a ring of vertices for each step. The profile must go from top to bottom along the outside of the surface to face outwards.
+// A step with a radius of 0 is a pole, which gets a single triangle per segment instead of a quad
+func lathe(b *MeshBuilder, profile []latheStep, segments int) {
+	if segments < minPrimitiveSegments { segments = minPrimitiveSegments }
+
+	first := uint32(b.VertexCount())
+	for _, step := range profile {
+		for j := 0; j <= segments; j++ {
+			u := float32(j) / float32(segments)
+			sin, cos := sincos(2 * math.Pi * float64(u))
+			b.AddVertex(Vertex{
+				Position: Vec3{step.radius * cos, step.radius * sin, step.z},
+				Normal: Vec3{step.normal[0] * cos, step.normal[0] * sin, step.normal[1]},
+				Color: White,
+				TexCoord: Vec2{u, step.v},
+			})
+		}
+	}
+
+	rowLen := uint32(segments + 1)
+	for i := 0; i + 1 < len(profile); i++ {
+		for j := uint32(0); j < uint32(segments); j++ {
+			topLeft := first + (uint32(i) * rowLen) + j
+			bottomLeft := topLeft + rowLen
+			if profile[i + 1].radius != 0 {
+				b.AddTriangle(topLeft, bottomLeft, bottomLeft + 1)
+			}
+			if profile[i].radius != 0 {
+				b.AddTriangle(topLeft, bottomLeft + 1, topLeft + 1)
+			}
+		}
+	}
+}
+
+// Adds a flat disk at height z facing up or down. The texture is mapped so that it is upright when viewed from outside, oriented towards +Y
+func addDisk(b *MeshBuilder, radius, z float32, segments int, up bool) {
+	if segments < minPrimitiveSegments { segments = minPrimitiveSegments }
+
+	normal := Vec3{0, 0, 1}
+	flip := float32(1)
+	if !up {
+		normal = Vec3{0, 0, -1}
+		flip = -1 // Viewed from below, +X is on the left
+	}
+
+	center := b.AddVertex(Vertex{
+		Position: Vec3{0, 0, z},
+		Normal: normal,
+		Color: White,
+		TexCoord: Vec2{0.5, 0.5},
+	})
+	for j := 0; j <= segments; j++ {
+		sin, cos := sincos(2 * math.Pi * float64(j) / float64(segments))
+		b.AddVertex(Vertex{
+			Position: Vec3{radius * cos, radius * sin, z},
+			Normal: normal,
+			Color: White,
+			TexCoord: Vec2{0.5 + (0.5 * cos * flip), 0.5 - (0.5 * sin)},
+		})
+	}
+	for j := uint32(1); j <= uint32(segments); j++ {
+		if up {
+			b.AddTriangle(center, center + j, center + j + 1)
+		} else {
+			b.AddTriangle(center, center + j + 1, center + j)
+		}
+	}
+}
+
+func buildPrimitive(b *MeshBuilder) *Mesh {
+	mesh := b.Build()
+	mesh.RecalculateTangents()
+	return mesh
+}
+
+func sincos(angle float64) (float32, float32) {
+	sin, cos := math.Sincos(angle)
+	return float32(sin), float32(cos)
+}
@@ -0,0 +1,139 @@
+package glitch
+
+import (
+	"math"
+	"testing"
+)
+
+// Returns the volume enclosed by the triangles, which is only correct if they are closed and wound outwards
+func meshVolume(mesh *Mesh) float32 {
+	pos := mesh.Positions()
+	inds := mesh.Indices()
+	volume := float32(0)
+	for i := 0; i + 2 < len(inds); i += 3 {
+		volume += pos[inds[i]].Dot(pos[inds[i+1]].Cross(pos[inds[i+2]])) / 6
+	}
+	return volume
+}
+
+func TestPrimitives(t *testing.T) {
+	tests := []struct{
+		name string
+		mesh *Mesh
+		bounds Box
+		volume float32 // 0 for open meshes
+		convex bool
+		wrapsU bool
+	}{
+		{"cube", NewCubeMesh(2), Box{Vec3{-1, -1, -1}, Vec3{1, 1, 1}}, 8, true, false},
+		{"box", NewBoxMesh(Vec3{2, 4, 6}), Box{Vec3{-1, -2, -3}, Vec3{1, 2, 3}}, 48, true, false},
+		{"plane", NewPlaneMesh(Vec2{4, 2}), Box{Vec3{-2, -1, 0}, Vec3{2, 1, 0}}, 0, false, false},
+		{"grid", NewGridMesh(Vec2{4, 2}, 4, 3), Box{Vec3{-2, -1, 0}, Vec3{2, 1, 0}}, 0, false, false},
+		{"uvsphere", NewUVSphereMesh(2, 64, 32), Box{Vec3{-2, -2, -2}, Vec3{2, 2, 2}}, 4.0 / 3 * math.Pi * 8, true, false},
+		{"icosphere", NewIcoSphereMesh(2, 3), Box{Vec3{-2, -2, -2}, Vec3{2, 2, 2}}, 4.0 / 3 * math.Pi * 8, true, true},
+		{"cylinder", NewCylinderMesh(1, 4, 64), Box{Vec3{-1, -1, -2}, Vec3{1, 1, 2}}, math.Pi * 4, true, false},
+		{"cone", NewConeMesh(1, 3, 64), Box{Vec3{-1, -1, -1.5}, Vec3{1, 1, 1.5}}, math.Pi, true, false},
+		{"capsule", NewCapsuleMesh(1, 4, 64, 16), Box{Vec3{-1, -1, -2}, Vec3{1, 1, 2}}, (math.Pi * 2) + (4.0 / 3 * math.Pi), true, false},
+		{"sphere capsule", NewCapsuleMesh(1, 1, 64, 16), Box{Vec3{-1, -1, -1}, Vec3{1, 1, 1}}, 4.0 / 3 * math.Pi, true, false},
+		{"torus", NewTorusMesh(3, 1, 64, 32), Box{Vec3{-4, -4, -1}, Vec3{4, 4, 1}}, 2 * math.Pi * math.Pi * 3, false, false},
+	}
+
+	for _, test := range tests {
+		mesh := test.mesh
+		pos := mesh.Positions()
+		normals := mesh.Normals()
+		tangents := mesh.Tangents()
+		uvs := mesh.TexCoords()
+		if len(normals) != len(pos) || len(tangents) != len(pos) || len(uvs) != len(pos) {
+			t.Fatalf("%s: Missing attributes", test.name)
+		}
+
+		for i := 0; i < 3; i++ {
+			if math.Abs(float64(mesh.Bounds().Min[i] - test.bounds.Min[i])) > 1e-4 || math.Abs(float64(mesh.Bounds().Max[i] - test.bounds.Max[i])) > 1e-4 {
+				t.Fatalf("%s: Unexpected bounds: %v", test.name, mesh.Bounds())
+			}
+		}
+
+		if test.volume != 0 {
+			volume := meshVolume(mesh)
+			if math.Abs(float64(volume - test.volume)) > 0.01 * float64(test.volume) {
+				t.Fatalf("%s: Unexpected volume: %v, want %v", test.name, volume, test.volume)
+			}
+		}
+
+		for i := range pos {
+			n := normals[i]
+			tan := Vec3{tangents[i][0], tangents[i][1], tangents[i][2]}
+			if math.Abs(float64(n.Len() - 1)) > 1e-4 || math.Abs(float64(tan.Len() - 1)) > 1e-4 {
+				t.Fatalf("%s: Vertex %d: Normal and tangent must be unit length: %v %v", test.name, i, n, tan)
+			}
+			if math.Abs(float64(n.Dot(tan))) > 1e-4 {
+				t.Fatalf("%s: Vertex %d: Tangent isn't perpendicular to the normal: %v %v", test.name, i, n, tan)
+			}
+			if w := tangents[i][3]; w != 1 && w != -1 {
+				t.Fatalf("%s: Vertex %d: Bad tangent handedness: %v", test.name, i, w)
+			}
+
+			maxU := float32(1)
+			if test.wrapsU {
+				maxU = 1.5
+			}
+			if uvs[i][0] < 0 || uvs[i][0] > maxU || uvs[i][1] < 0 || uvs[i][1] > 1 {
+				t.Fatalf("%s: Vertex %d: Texture coordinate is out of range: %v", test.name, i, uvs[i])
+			}
+		}
+
+		inds := mesh.Indices()
+		for i := 0; i + 2 < len(inds); i += 3 {
+			a, b, c := inds[i], inds[i+1], inds[i+2]
+			face := pos[b].Sub(pos[a]).Cross(pos[c].Sub(pos[a]))
+			if face.Len() < 1e-7 {
+				t.Fatalf("%s: Triangle %d is degenerate", test.name, i / 3)
+			}
+			smooth := normals[a].Add(normals[b]).Add(normals[c])
+			if face.Dot(smooth) <= 0 {
+				t.Fatalf("%s: Triangle %d is wound against its normals", test.name, i / 3)
+			}
+			if test.convex {
+				centroid := pos[a].Add(pos[b]).Add(pos[c])
+				if face.Dot(centroid) <= 0 {
+					t.Fatalf("%s: Triangle %d faces inwards", test.name, i / 3)
+				}
+			}
+		}
+	}
+}
+
+func TestBoxTangents(t *testing.T) {
+	mesh := NewCubeMesh(2)
+	for i, p := range mesh.Positions() {
+		if mesh.Normals()[i] != (Vec3{0, 0, 1}) { continue }
+
+		// The top face has u along +X and v along -Y
+		tan := mesh.Tangents()[i]
+		if math.Abs(float64(tan[0] - 1)) > 1e-5 || tan[3] != -1 {
+			t.Fatalf("Unexpected tangent at %v: %v", p, tan)
+		}
+		uv := mesh.TexCoords()[i]
+		if uv != (Vec2{(p[0] + 1) / 2, (1 - p[1]) / 2}) {
+			t.Fatalf("Unexpected texture coordinate at %v: %v", p, uv)
+		}
+	}
+}
+
+func TestRecalculateTangentsWithoutTexCoords(t *testing.T) {
+	b := NewMeshBuilder()
+	b.AddTriangle(
+		b.AddVertex(Vertex{Position: Vec3{0, 0, 0}, Normal: Vec3{1, 0, 0}}),
+		b.AddVertex(Vertex{Position: Vec3{0, 1, 0}, Normal: Vec3{1, 0, 0}}),
+		b.AddVertex(Vertex{Position: Vec3{0, 0, 1}, Normal: Vec3{1, 0, 0}}),
+	)
+	mesh := b.Build()
+	mesh.RecalculateTangents()
+	for _, tan := range mesh.Tangents() {
+		vec := Vec3{tan[0], tan[1], tan[2]}
+		if math.Abs(float64(vec.Len() - 1)) > 1e-5 || math.Abs(float64(vec[0])) > 1e-5 {
+			t.Fatalf("Expected a perpendicular fallback tangent: %v", tan)
+		}
+	}
+}
@@ -119,6 +119,12 @@ func loadShader(shaderType gl.Enum, src string) (gl.Shader, error) {
 	return shader, nil
 }
 
+// Returns true if the uniform was listed in the shader's uniform format
+func (s *Shader) hasUniform(name string) bool {
+	_, ok := s.uniforms[name]
+	return ok
+}
+
 func (s *Shader) SetUniform(uniformName string, value interface{}) bool {
 	ret := false
 	mainthread.Call(func() {
@@ -131,9 +137,13 @@ func (s *Shader) SetUniform(uniformName string, value interface{}) bool {
 		}
 
 		switch val := value.(type) {
+		case int32:
+			gl.Uniform1i(uniform.loc, int(val))
 		case float32:
 			sliced := []float32{val}
 			gl.Uniform1fv(uniform.loc, sliced)
+		case Vec2:
+			gl.Uniform2fv(uniform.loc, val[:])
 		case Vec3:
 			gl.Uniform3fv(uniform.loc, val[:])
 		case Vec4:
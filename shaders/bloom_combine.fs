@@ -0,0 +1,21 @@
+#version 300 es
+
+// Required for webgl
+#ifdef GL_ES
+precision highp float;
+#endif
+
+out vec4 FragColor;
+
+in vec2 TexCoord;
+
+uniform sampler2D texture1; // The blurred bright pixels
+uniform sampler2D original;
+uniform float intensity;
+
+void main()
+{
+  vec4 bloom = texture(texture1, TexCoord);
+  vec4 color = texture(original, TexCoord);
+  FragColor = vec4(color.rgb + (bloom.rgb * intensity), max(color.a, bloom.a * intensity));
+}
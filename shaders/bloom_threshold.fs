@@ -0,0 +1,23 @@
+#version 300 es
+
+// Required for webgl
+#ifdef GL_ES
+precision highp float;
+#endif
+
+out vec4 FragColor;
+
+in vec2 TexCoord;
+
+uniform sampler2D texture1;
+uniform float threshold;
+
+void main()
+{
+  vec4 color = texture(texture1, TexCoord);
+  float luma = dot(color.rgb, vec3(0.2126, 0.7152, 0.0722));
+
+  // Only keep the part of the color that is brighter than the threshold
+  float scale = max(luma - threshold, 0.0) / max(luma, 0.0001);
+  FragColor = color * scale;
+}
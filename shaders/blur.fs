@@ -0,0 +1,29 @@
+#version 300 es
+
+// Required for webgl
+#ifdef GL_ES
+precision highp float;
+#endif
+
+out vec4 FragColor;
+
+in vec2 TexCoord;
+
+uniform sampler2D texture1;
+uniform vec2 texelSize;
+uniform vec2 direction; // (1, 0) for a horizontal pass, (0, 1) for a vertical pass
+
+// 9 tap gaussian, split into a center tap and 4 taps on each side
+const float weights[5] = float[](0.227027, 0.1945946, 0.1216216, 0.054054, 0.016216);
+
+void main()
+{
+  vec2 step = direction * texelSize;
+  vec4 color = texture(texture1, TexCoord) * weights[0];
+  for (int i = 1; i < 5; i++) {
+    vec2 offset = step * float(i);
+    color += texture(texture1, TexCoord + offset) * weights[i];
+    color += texture(texture1, TexCoord - offset) * weights[i];
+  }
+  FragColor = color;
+}
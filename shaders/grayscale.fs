@@ -0,0 +1,20 @@
+#version 300 es
+
+// Required for webgl
+#ifdef GL_ES
+precision highp float;
+#endif
+
+out vec4 FragColor;
+
+in vec2 TexCoord;
+
+uniform sampler2D texture1;
+uniform float amount; // 0 is the original color, 1 is fully gray
+
+void main()
+{
+  vec4 color = texture(texture1, TexCoord);
+  float luma = dot(color.rgb, vec3(0.2126, 0.7152, 0.0722));
+  FragColor = vec4(mix(color.rgb, vec3(luma), amount), color.a);
+}
@@ -0,0 +1,32 @@
+#version 300 es
+
+// Required for webgl
+#ifdef GL_ES
+precision highp float;
+#endif
+
+out vec4 FragColor;
+
+in vec4 ourColor;
+in vec2 TexCoord; // Runs from -1 to 1 across the light's radius
+
+uniform float falloff;
+uniform vec2 spotDirection;
+uniform float spotCos; // Cosine of half the cone angle. Point lights use a value below -1
+uniform float spotEdge; // How far into the cone (in cosine) the edge fades over
+
+void main()
+{
+  float dist = length(TexCoord);
+  if (dist >= 1.0) {
+    discard;
+  }
+
+  float attenuation = pow(1.0 - dist, falloff);
+  if (spotCos >= -1.0 && dist > 0.0) {
+    float c = dot(TexCoord / dist, spotDirection);
+    attenuation *= smoothstep(spotCos, spotCos + spotEdge, c);
+  }
+
+  FragColor = vec4(ourColor.rgb * attenuation, 1.0);
+}
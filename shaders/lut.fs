@@ -0,0 +1,39 @@
+#version 300 es
+
+// Required for webgl
+#ifdef GL_ES
+precision highp float;
+#endif
+
+out vec4 FragColor;
+
+in vec2 TexCoord;
+
+uniform sampler2D texture1;
+uniform sampler2D lut; // A (size * size) x size strip. Red increases across each slice, green increases down, blue selects the slice
+uniform float lutSize;
+uniform float intensity;
+
+void main()
+{
+  vec4 color = texture(texture1, TexCoord);
+
+  // Colors are premultiplied, so they need to be unpremultiplied before looking them up
+  vec3 rgb = vec3(0.0);
+  if (color.a > 0.0) {
+    rgb = clamp(color.rgb / color.a, 0.0, 1.0);
+  }
+
+  float blue = rgb.b * (lutSize - 1.0);
+  float slice0 = floor(blue);
+  float slice1 = min(slice0 + 1.0, lutSize - 1.0);
+
+  // Sample the center of the texels so that neighboring slices don't bleed in
+  float x = ((rgb.r * (lutSize - 1.0)) + 0.5) / (lutSize * lutSize);
+  float y = ((rgb.g * (lutSize - 1.0)) + 0.5) / lutSize;
+  vec3 a = texture(lut, vec2(x + (slice0 / lutSize), y)).rgb;
+  vec3 b = texture(lut, vec2(x + (slice1 / lutSize), y)).rgb;
+  vec3 graded = mix(a, b, blue - slice0);
+
+  FragColor = vec4(mix(rgb, graded, intensity) * color.a, color.a);
+}
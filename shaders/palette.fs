@@ -0,0 +1,29 @@
+#version 300 es
+
+// Required for webgl
+#ifdef GL_ES
+precision highp float;
+#endif
+
+out vec4 FragColor;
+
+in vec4 ourColor;
+in vec2 TexCoord;
+
+uniform sampler2D texture1; // The index texture
+uniform sampler2D palette;
+
+void main()
+{
+  vec4 index = texture(texture1, TexCoord);
+  if (index.a == 0.0) {
+    discard;
+  }
+
+  // The red channel of the index selects the column, the red channel of the color mask selects the row (See glitch.PaletteMask)
+  int column = int(floor((index.r * 255.0) + 0.5));
+  int row = int(floor((ourColor.r * 255.0) + 0.5));
+  vec4 color = texelFetch(palette, ivec2(column, row), 0);
+
+  FragColor = color * ourColor.a;
+}
@@ -0,0 +1,159 @@
+package shaders
+
+import (
+	_ "embed"
+
+	"github.com/unitoftime/glitch"
+)
+
+// Post effects draw a single fullscreen quad, so they only need positions and texture coordinates
+var PostVertexFormat = glitch.VertexFormat{
+	VertexAttribute("positionIn", glitch.AttrVec2, glitch.PositionXY),
+	VertexAttribute("texCoordIn", glitch.AttrVec2, glitch.TexCoordXY),
+}
+
+//go:embed post.vs
+var PostVertexShader string;
+
+//go:embed copy.fs
+var CopyFragmentShader string;
+
+//go:embed blur.fs
+var GaussianBlurFragmentShader string;
+
+//go:embed bloom_threshold.fs
+var BloomThresholdFragmentShader string;
+
+//go:embed bloom_combine.fs
+var BloomCombineFragmentShader string;
+
+//go:embed grayscale.fs
+var GrayscaleFragmentShader string;
+
+//go:embed lut.fs
+var LUTFragmentShader string;
+
+// Draws the input unchanged
+var CopyShader = glitch.ShaderConfig{
+	VertexShader: PostVertexShader,
+	FragmentShader: CopyFragmentShader,
+	VertexFormat: PostVertexFormat,
+	UniformFormat: glitch.UniformFormat{},
+}
+
+var GaussianBlurShader = glitch.ShaderConfig{
+	VertexShader: PostVertexShader,
+	FragmentShader: GaussianBlurFragmentShader,
+	VertexFormat: PostVertexFormat,
+	UniformFormat: glitch.UniformFormat{
+		glitch.Attr{"texelSize", glitch.AttrVec2},
+		glitch.Attr{"direction", glitch.AttrVec2},
+	},
+}
+
+var BloomThresholdShader = glitch.ShaderConfig{
+	VertexShader: PostVertexShader,
+	FragmentShader: BloomThresholdFragmentShader,
+	VertexFormat: PostVertexFormat,
+	UniformFormat: glitch.UniformFormat{
+		glitch.Attr{"threshold", glitch.AttrFloat},
+	},
+}
+
+var BloomCombineShader = glitch.ShaderConfig{
+	VertexShader: PostVertexShader,
+	FragmentShader: BloomCombineFragmentShader,
+	VertexFormat: PostVertexFormat,
+	UniformFormat: glitch.UniformFormat{
+		glitch.Attr{"original", glitch.AttrInt},
+		glitch.Attr{"intensity", glitch.AttrFloat},
+	},
+}
+
+var GrayscaleShader = glitch.ShaderConfig{
+	VertexShader: PostVertexShader,
+	FragmentShader: GrayscaleFragmentShader,
+	VertexFormat: PostVertexFormat,
+	UniformFormat: glitch.UniformFormat{
+		glitch.Attr{"amount", glitch.AttrFloat},
+	},
+}
+
+var LUTShader = glitch.ShaderConfig{
+	VertexShader: PostVertexShader,
+	FragmentShader: LUTFragmentShader,
+	VertexFormat: PostVertexFormat,
+	UniformFormat: glitch.UniformFormat{
+		glitch.Attr{"lut", glitch.AttrInt},
+		glitch.Attr{"lutSize", glitch.AttrFloat},
+		glitch.Attr{"intensity", glitch.AttrFloat},
+	},
+}
+
+// Returns a horizontal and a vertical blur pass, which should be applied in that order
+func NewGaussianBlur() ([]*glitch.PostEffect, error) {
+	shader, err := glitch.NewShader(GaussianBlurShader)
+	if err != nil {
+		return nil, err
+	}
+
+	horizontal := glitch.NewPostEffect(shader)
+	horizontal.SetUniform("direction", glitch.Vec2{1, 0})
+
+	vertical := glitch.NewPostEffect(shader)
+	vertical.SetUniform("direction", glitch.Vec2{0, 1})
+
+	return []*glitch.PostEffect{horizontal, vertical}, nil
+}
+
+// Returns the passes of a bloom effect: Extract the pixels brighter than the threshold, blur them, then add them back onto the original image
+func NewBloom(threshold, intensity float32) ([]*glitch.PostEffect, error) {
+	thresholdShader, err := glitch.NewShader(BloomThresholdShader)
+	if err != nil {
+		return nil, err
+	}
+	combineShader, err := glitch.NewShader(BloomCombineShader)
+	if err != nil {
+		return nil, err
+	}
+	blur, err := NewGaussianBlur()
+	if err != nil {
+		return nil, err
+	}
+
+	extract := glitch.NewPostEffect(thresholdShader)
+	extract.SetUniform("threshold", threshold)
+
+	combine := glitch.NewPostEffect(combineShader)
+	combine.SetUniform("intensity", intensity)
+
+	effects := []*glitch.PostEffect{extract}
+	effects = append(effects, blur...)
+	effects = append(effects, combine)
+	return effects, nil
+}
+
+// Desaturates the image. An amount of 1 is fully gray
+func NewGrayscale(amount float32) (*glitch.PostEffect, error) {
+	shader, err := glitch.NewShader(GrayscaleShader)
+	if err != nil {
+		return nil, err
+	}
+	effect := glitch.NewPostEffect(shader)
+	effect.SetUniform("amount", amount)
+	return effect, nil
+}
+
+// Color grades the image with a lookup table. The LUT must be a (size * size) x size strip, where red increases across each slice, green increases down, and blue selects the slice (The common 256x16 layout).
+// The LUT should be created with linear filtering and clamped wrapping. An intensity of 1 fully applies the grade
+func NewLUT(lut *glitch.Texture, intensity float32) (*glitch.PostEffect, error) {
+	shader, err := glitch.NewShader(LUTShader)
+	if err != nil {
+		return nil, err
+	}
+	effect := glitch.NewPostEffect(shader)
+	effect.SetTexture("lut", lut)
+	effect.SetUniform("lutSize", lut.Bounds().H())
+	effect.SetUniform("intensity", intensity)
+	return effect, nil
+}
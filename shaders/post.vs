@@ -0,0 +1,13 @@
+#version 300 es
+
+layout (location = 0) in vec2 positionIn;
+layout (location = 1) in vec2 texCoordIn;
+
+out vec2 TexCoord;
+
+// Positions are already in clip space, so there are no matrices
+void main()
+{
+  gl_Position = vec4(positionIn, 0.0, 1.0);
+  TexCoord = texCoordIn;
+}
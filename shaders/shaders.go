@@ -93,6 +93,60 @@ var PixelArtShader2 = glitch.ShaderConfig{
 	},
 }
 
+//go:embed palette.fs
+var PaletteFragmentShader string;
+
+// Draws palette indexed sprites (See glitch.PaletteMaterial). The "palette" uniform must be set to glitch.PaletteTextureUnit
+var PaletteShader = glitch.ShaderConfig{
+	VertexShader: SpriteVertexShader,
+	FragmentShader: PaletteFragmentShader,
+	VertexFormat: glitch.VertexFormat{
+		VertexAttribute("positionIn", glitch.AttrVec2, glitch.PositionXY),
+		VertexAttribute("colorIn", glitch.AttrVec4, glitch.ColorRGBA),
+		VertexAttribute("texCoordIn", glitch.AttrVec2, glitch.TexCoordXY),
+	},
+	UniformFormat: glitch.UniformFormat{
+		glitch.Attr{"projection", glitch.AttrMat4},
+		glitch.Attr{"view", glitch.AttrMat4},
+		glitch.Attr{"palette", glitch.AttrInt},
+	},
+}
+
+//go:embed light2d.fs
+var Light2DFragmentShader string;
+
+// Draws the lights of a glitch.LightPass
+var Light2DShader = glitch.ShaderConfig{
+	VertexShader: SpriteVertexShader,
+	FragmentShader: Light2DFragmentShader,
+	VertexFormat: glitch.VertexFormat{
+		VertexAttribute("positionIn", glitch.AttrVec2, glitch.PositionXY),
+		VertexAttribute("colorIn", glitch.AttrVec4, glitch.ColorRGBA),
+		VertexAttribute("texCoordIn", glitch.AttrVec2, glitch.TexCoordXY),
+	},
+	UniformFormat: glitch.UniformFormat{
+		glitch.Attr{"projection", glitch.AttrMat4},
+		glitch.Attr{"view", glitch.AttrMat4},
+		glitch.Attr{"falloff", glitch.AttrFloat},
+		glitch.Attr{"spotDirection", glitch.AttrVec2},
+		glitch.Attr{"spotCos", glitch.AttrFloat},
+		glitch.Attr{"spotEdge", glitch.AttrFloat},
+	},
+}
+
+// Creates a 2D light pass with the stock light and composite shaders
+func NewLightPass(bounds glitch.Rect) (*glitch.LightPass, error) {
+	lightShader, err := glitch.NewShader(Light2DShader)
+	if err != nil {
+		return nil, err
+	}
+	compositeShader, err := glitch.NewShader(CopyShader)
+	if err != nil {
+		return nil, err
+	}
+	return glitch.NewLightPass(bounds, lightShader, compositeShader)
+}
+
 //go:embed mesh.vs
 var DiffuseVertexShader string;
 
@@ -0,0 +1,310 @@
+package glitch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// A collection of named sprites, nine panels, and animations which were sliced out of a single texture
+// Supports the TexturePacker JSON hash and array formats and the Aseprite JSON export (which uses the same formats plus frame durations, tags, and slices)
+type Spritesheet struct {
+	texture *Texture
+	names []string // Frame names in the order they were listed in the file
+	sprites map[string]*Sprite
+	durations map[string]time.Duration
+	ninePanels map[string]*NinePanelSprite
+	animations map[string]Animation
+}
+
+type jsonRect struct {
+	X, Y, W, H float32
+}
+
+type jsonSize struct {
+	W, H float32
+}
+
+type jsonPoint struct {
+	X, Y float32
+}
+
+type jsonFrame struct {
+	Filename string `json:"filename"`
+	Frame jsonRect `json:"frame"`
+	Rotated bool `json:"rotated"`
+	Trimmed bool `json:"trimmed"`
+	SpriteSourceSize jsonRect `json:"spriteSourceSize"`
+	SourceSize jsonSize `json:"sourceSize"`
+	Pivot *jsonPoint `json:"pivot"` // TexturePacker: normalized to the source size
+	Scale9Borders *jsonRect `json:"scale9Borders"` // TexturePacker: X, Y are the left and top borders, W, H are the size of the center
+	Duration int `json:"duration"` // Aseprite: milliseconds
+}
+
+type jsonFrameTag struct {
+	Name string `json:"name"`
+	From int `json:"from"`
+	To int `json:"to"`
+	Direction string `json:"direction"`
+}
+
+type jsonSliceKey struct {
+	Frame int `json:"frame"`
+	Bounds jsonRect `json:"bounds"`
+	Center *jsonRect `json:"center"`
+}
+
+type jsonSlice struct {
+	Name string `json:"name"`
+	Keys []jsonSliceKey `json:"keys"`
+}
+
+type jsonSpritesheet struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta struct {
+		Image string `json:"image"`
+		FrameTags []jsonFrameTag `json:"frameTags"`
+		Slices []jsonSlice `json:"slices"`
+	} `json:"meta"`
+}
+
+// Slices a texture into sprites based on TexturePacker or Aseprite JSON data.
+func NewSpritesheet(texture *Texture, jsonData []byte) (*Spritesheet, error) {
+	var data jsonSpritesheet
+	err := json.Unmarshal(jsonData, &data)
+	if err != nil {
+		return nil, fmt.Errorf("NewSpritesheet: %w", err)
+	}
+
+	frames, err := decodeFrames(data.Frames)
+	if err != nil {
+		return nil, fmt.Errorf("NewSpritesheet: %w", err)
+	}
+
+	sheet := &Spritesheet{
+		texture: texture,
+		names: make([]string, 0, len(frames)),
+		sprites: make(map[string]*Sprite),
+		durations: make(map[string]time.Duration),
+		ninePanels: make(map[string]*NinePanelSprite),
+		animations: make(map[string]Animation),
+	}
+
+	for _, f := range frames {
+		if _, exists := sheet.sprites[f.Filename]; exists {
+			return nil, fmt.Errorf("NewSpritesheet: duplicate frame name: %s", f.Filename)
+		}
+		sheet.names = append(sheet.names, f.Filename)
+		sheet.sprites[f.Filename] = newFrameSprite(texture, f)
+		sheet.durations[f.Filename] = time.Duration(f.Duration) * time.Millisecond
+
+		if f.Scale9Borders != nil {
+			b := f.Scale9Borders
+			border := R(b.X, f.Frame.H - b.Y - b.H, f.Frame.W - b.X - b.W, b.Y)
+			sheet.ninePanels[f.Filename] = NewNinePanelSprite(texture, frameRect(f), border)
+		}
+	}
+
+	for _, tag := range data.Meta.FrameTags {
+		anim, err := sheet.tagAnimation(tag)
+		if err != nil {
+			return nil, fmt.Errorf("NewSpritesheet: %w", err)
+		}
+		sheet.animations[tag.Name] = anim
+	}
+
+	for _, slice := range data.Meta.Slices {
+		if len(slice.Keys) <= 0 { continue }
+		key := slice.Keys[0] // TODO - Slices can be keyed per frame, but we only use the first key
+		if key.Center == nil { continue } // Only nine-slices are converted
+
+		if key.Frame < 0 || key.Frame >= len(frames) {
+			return nil, fmt.Errorf("NewSpritesheet: slice %s references missing frame %d", slice.Name, key.Frame)
+		}
+		f := frames[key.Frame]
+
+		// Slice bounds are relative to the untrimmed source image, so move them into the texture
+		x := f.Frame.X + key.Bounds.X - f.SpriteSourceSize.X
+		y := f.Frame.Y + key.Bounds.Y - f.SpriteSourceSize.Y
+		bounds := R(x, y, x + key.Bounds.W, y + key.Bounds.H)
+
+		c := key.Center
+		border := R(c.X, key.Bounds.H - c.Y - c.H, key.Bounds.W - c.X - c.W, c.Y)
+		sheet.ninePanels[slice.Name] = NewNinePanelSprite(texture, bounds, border)
+	}
+
+	return sheet, nil
+}
+
+// Decodes either the hash format (an object keyed by frame name) or the array format (a list of frames with filenames).
+// The hash format is walked token by token so that the order of the frames is preserved
+func decodeFrames(raw json.RawMessage) ([]jsonFrame, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("missing frames")
+	}
+
+	if raw[0] == '[' {
+		frames := make([]jsonFrame, 0)
+		err := json.Unmarshal(raw, &frames)
+		return frames, err
+	}
+
+	frames := make([]jsonFrame, 0)
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	_, err := dec.Token() // Opening brace
+	if err != nil {
+		return nil, err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid frame key: %v", tok)
+		}
+
+		var f jsonFrame
+		err = dec.Decode(&f)
+		if err != nil {
+			return nil, err
+		}
+		f.Filename = name
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+// Returns the region of the texture that holds the frame
+func frameRect(f jsonFrame) Rect {
+	w, h := f.Frame.W, f.Frame.H
+	if f.Rotated {
+		// Rotated frames are stored turned 90 degrees clockwise, so they take up h x w in the texture
+		w, h = h, w
+	}
+	return R(f.Frame.X, f.Frame.Y, f.Frame.X + w, f.Frame.Y + h)
+}
+
+// Creates a sprite for the frame. Trimmed and pivoted frames have their mesh offset so that drawing them lands in the same spot as the untrimmed image would
+func newFrameSprite(texture *Texture, f jsonFrame) *Sprite {
+	bounds := frameRect(f)
+	sprite := NewSprite(texture, bounds)
+
+	// Note: Frame rect and source size are in image coordinates (y down), the mesh is y up
+	w, h := f.Frame.W, f.Frame.H
+	sw, sh := f.SourceSize.W, f.SourceSize.H
+	if sw <= 0 || sh <= 0 {
+		sw, sh = w, h
+	}
+
+	offset := Vec2{0, 0}
+	if f.Trimmed {
+		offset[0] = f.SpriteSourceSize.X + (w / 2) - (sw / 2)
+		offset[1] = -(f.SpriteSourceSize.Y + (h / 2) - (sh / 2))
+	}
+	if f.Pivot != nil {
+		offset[0] -= (f.Pivot.X * sw) - (sw / 2)
+		offset[1] += (f.Pivot.Y * sh) - (sh / 2)
+	}
+
+	if f.Rotated {
+		uv := sprite.mesh.texCoords
+		// Rotate the texture coordinates back by 90 degrees
+		uv[0], uv[1], uv[2], uv[3] = uv[1], uv[2], uv[3], uv[0]
+		sprite.mesh.positions = NewSpriteMesh(w, h, R(0, 0, 1, 1)).positions
+	}
+
+	if offset != (Vec2{0, 0}) {
+		positions := make([]Vec3, len(sprite.mesh.positions))
+		for i := range positions {
+			positions[i] = sprite.mesh.positions[i].Add(offset.Vec3())
+		}
+		sprite.mesh.positions = positions
+	}
+	sprite.mesh.bounds = R(-w/2 + offset[0], -h/2 + offset[1], w/2 + offset[0], h/2 + offset[1]).ToBox()
+
+	// The sprite's bounds are the size it draws at (See RectDraw), which for rotated frames is the unrotated size
+	if f.Rotated {
+		sprite.bounds = R(f.Frame.X, f.Frame.Y, f.Frame.X + w, f.Frame.Y + h)
+	}
+
+	return sprite
+}
+
+func (s *Spritesheet) tagAnimation(tag jsonFrameTag) (Animation, error) {
+	if tag.From < 0 || tag.To >= len(s.names) || tag.From > tag.To {
+		return Animation{}, fmt.Errorf("frame tag %s has invalid range [%d, %d]", tag.Name, tag.From, tag.To)
+	}
+
+	anim := Animation{
+		Name: tag.Name,
+		Frames: make([]AnimationFrame, 0, tag.To - tag.From + 1),
+	}
+	for i := tag.From; i <= tag.To; i++ {
+		name := s.names[i]
+		anim.Frames = append(anim.Frames, AnimationFrame{
+			Sprite: s.sprites[name],
+			Duration: s.durations[name],
+		})
+	}
+
+	switch tag.Direction {
+	case "", "forward":
+	case "reverse":
+		reverseFrames(anim.Frames)
+	case "pingpong":
+		anim.PingPong = true
+	case "pingpong_reverse":
+		reverseFrames(anim.Frames)
+		anim.PingPong = true
+	default:
+		return Animation{}, fmt.Errorf("frame tag %s has unknown direction: %s", tag.Name, tag.Direction)
+	}
+
+	return anim, nil
+}
+
+func reverseFrames(frames []AnimationFrame) {
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+}
+
+func (s *Spritesheet) Texture() *Texture {
+	return s.texture
+}
+
+// Returns the sprite with the given frame name
+func (s *Spritesheet) Get(name string) (*Sprite, error) {
+	sprite, ok := s.sprites[name]
+	if !ok {
+		return nil, fmt.Errorf("Spritesheet: missing sprite: %s", name)
+	}
+	return sprite, nil
+}
+
+// Returns the nine panel sprite with the given frame or slice name
+func (s *Spritesheet) GetNinePanel(name string) (*NinePanelSprite, error) {
+	panel, ok := s.ninePanels[name]
+	if !ok {
+		return nil, fmt.Errorf("Spritesheet: missing nine panel: %s", name)
+	}
+	return panel, nil
+}
+
+// Returns the animation with the given tag name
+func (s *Spritesheet) GetAnimation(name string) (Animation, error) {
+	anim, ok := s.animations[name]
+	if !ok {
+		return Animation{}, fmt.Errorf("Spritesheet: missing animation: %s", name)
+	}
+	return anim, nil
+}
+
+// Returns the frame names in the order they were listed in the file
+func (s *Spritesheet) Names() []string {
+	return s.names
+}
@@ -0,0 +1,136 @@
+package glitch
+
+import (
+	"testing"
+	"time"
+)
+
+const asepriteHashJson = `{
+"frames": {
+	"walk 0.aseprite": {
+		"frame": { "x": 0, "y": 0, "w": 16, "h": 16 },
+		"rotated": false,
+		"trimmed": false,
+		"spriteSourceSize": { "x": 0, "y": 0, "w": 16, "h": 16 },
+		"sourceSize": { "w": 16, "h": 16 },
+		"duration": 100
+	},
+	"walk 1.aseprite": {
+		"frame": { "x": 16, "y": 0, "w": 8, "h": 8 },
+		"rotated": false,
+		"trimmed": true,
+		"spriteSourceSize": { "x": 8, "y": 0, "w": 8, "h": 8 },
+		"sourceSize": { "w": 16, "h": 16 },
+		"duration": 150
+	},
+	"walk 2.aseprite": {
+		"frame": { "x": 32, "y": 0, "w": 16, "h": 16 },
+		"rotated": false,
+		"trimmed": false,
+		"spriteSourceSize": { "x": 0, "y": 0, "w": 16, "h": 16 },
+		"sourceSize": { "w": 16, "h": 16 },
+		"duration": 200
+	}
+},
+"meta": {
+	"image": "walk.png",
+	"size": { "w": 64, "h": 64 },
+	"frameTags": [
+		{ "name": "walk", "from": 0, "to": 2, "direction": "reverse" },
+		{ "name": "bounce", "from": 1, "to": 2, "direction": "pingpong" }
+	],
+	"slices": [
+		{ "name": "panel", "keys": [{ "frame": 0, "bounds": {"x": 0, "y": 0, "w": 16, "h": 16 }, "center": {"x": 4, "y": 3, "w": 8, "h": 8 } }] }
+	]
+}
+}`
+
+const texturePackerArrayJson = `{"frames": [
+	{
+		"filename": "a.png",
+		"frame": {"x":0,"y":0,"w":10,"h":20},
+		"rotated": true,
+		"trimmed": false,
+		"spriteSourceSize": {"x":0,"y":0,"w":10,"h":20},
+		"sourceSize": {"w":10,"h":20},
+		"pivot": {"x":0.5,"y":1}
+	}
+],
+"meta": { "image": "sheet.png" }
+}`
+
+func TestSpritesheetAseprite(t *testing.T) {
+	texture := &Texture{width: 64, height: 64}
+	sheet, err := NewSpritesheet(texture, []byte(asepriteHashJson))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := sheet.Names()
+	if len(names) != 3 || names[0] != "walk 0.aseprite" || names[2] != "walk 2.aseprite" {
+		t.Fatalf("Frame order not preserved: %v", names)
+	}
+
+	// Trimmed frame should be shifted right by 4 and up by 4 from the center of the source image
+	sprite, err := sheet.Get("walk 1.aseprite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bounds := sprite.mesh.Bounds()
+	if bounds.Min != (Vec3{0, 0, 0}) || bounds.Max != (Vec3{8, 8, 0}) {
+		t.Fatalf("Wrong trimmed bounds: %v", bounds)
+	}
+
+	walk, err := sheet.GetAnimation("walk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(walk.Frames) != 3 || walk.Frames[0].Duration != 200 * time.Millisecond || walk.PingPong {
+		t.Fatalf("Wrong walk animation: %v", walk)
+	}
+
+	bounce, err := sheet.GetAnimation("bounce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bounce.Frames) != 2 || !bounce.PingPong {
+		t.Fatalf("Wrong bounce animation: %v", bounce)
+	}
+
+	panel, err := sheet.GetNinePanel("panel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if panel.Border() != R(4, 5, 4, 3) {
+		t.Fatalf("Wrong nine panel border: %v", panel.Border())
+	}
+}
+
+func TestSpritesheetTexturePackerArray(t *testing.T) {
+	texture := &Texture{width: 64, height: 64}
+	sheet, err := NewSpritesheet(texture, []byte(texturePackerArrayJson))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sprite, err := sheet.Get("a.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotated frames take up h x w in the texture, but draw at their unrotated size
+	if sprite.Bounds() != R(0, 0, 10, 20) {
+		t.Fatalf("Wrong rotated bounds: %v", sprite.Bounds())
+	}
+
+	// Pivot is at the bottom center, so the mesh should sit above the origin
+	bounds := sprite.mesh.Bounds()
+	if bounds.Min != (Vec3{-5, 0, 0}) || bounds.Max != (Vec3{5, 20, 0}) {
+		t.Fatalf("Wrong pivoted bounds: %v", bounds)
+	}
+
+	// RectDraw scales by the bounds, so they need to match the mesh
+	if sprite.Bounds().W() != bounds.Rect().W() || sprite.Bounds().H() != bounds.Rect().H() {
+		t.Fatalf("Bounds %v don't match the mesh %v", sprite.Bounds(), bounds)
+	}
+}
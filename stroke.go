@@ -0,0 +1,329 @@
+package glitch
+
+import (
+	"math"
+)
+
+// How the outer edges of two connected line segments are joined
+type LineJoin uint8
+const (
+	JoinMiter LineJoin = iota // Extends the outer edges until they meet. Falls back to a bevel when the point is longer than the miter limit
+	JoinRound
+	JoinBevel
+)
+
+// How the ends of an open line are drawn
+type LineCap uint8
+const (
+	CapButt LineCap = iota // Stops exactly at the end point
+	CapSquare // Extends past the end point by half of the width
+	CapRound
+)
+
+// The default ratio of miter length to line width, before a miter join is beveled
+const DefaultMiterLimit = 4
+
+// Strokes a line through the points with the GeomDraw's join, cap and dash settings. Closed lines connect the last point back to the first and are only capped when dashed.
+// Triangles never overlap, so translucent lines draw evenly. The exceptions are lines which cross themselves and very sharp corners between segments that are shorter than the width
+func (g *GeomDraw) Stroke(points []Vec3, width float32, closed bool) *Mesh {
+	s := &stroker{
+		g: g,
+		hw: width / 2,
+		miterLimit: g.MiterLimit,
+		positions: make([]Vec3, 0),
+		inds: make([]uint32, 0),
+	}
+	if s.miterLimit <= 0 {
+		s.miterLimit = DefaultMiterLimit
+	}
+
+	points = dedupPoints(points, closed)
+	if width > 0 && len(points) >= 2 {
+		if len(g.Dashes) > 0 {
+			for _, dash := range dashPath(points, closed, g.Dashes, g.DashOffset) {
+				s.stroke(dedupPoints(dash, false), false)
+			}
+		} else {
+			s.stroke(points, closed)
+		}
+	}
+
+	return g.fillMesh(s.positions, s.inds)
+}
+
+// Removes repeated points, which have no direction to stroke in
+func dedupPoints(points []Vec3, closed bool) []Vec3 {
+	ret := make([]Vec3, 0, len(points))
+	for _, p := range points {
+		if len(ret) > 0 && ret[len(ret)-1].Vec2() == p.Vec2() { continue }
+		ret = append(ret, p)
+	}
+	if closed && len(ret) > 1 && ret[0].Vec2() == ret[len(ret)-1].Vec2() {
+		ret = ret[:len(ret)-1]
+	}
+	return ret
+}
+
+// Splits the line into the "on" sections of the dash pattern. The pattern alternates between on and off lengths, and is repeated twice if it has an odd length
+func dashPath(points []Vec3, closed bool, dashes []float32, offset float32) [][]Vec3 {
+	if len(dashes) % 2 == 1 {
+		dashes = append(append([]float32{}, dashes...), dashes...)
+	}
+	total := float32(0)
+	for _, d := range dashes {
+		if d < 0 {
+			return [][]Vec3{points} // Invalid pattern, draw a solid line
+		}
+		total += d
+	}
+	if total <= 0 {
+		return [][]Vec3{points}
+	}
+
+	if closed {
+		points = append(append([]Vec3{}, points...), points[0])
+	}
+
+	// Skip ahead through the pattern by the offset
+	offset = float32(math.Mod(float64(offset), float64(total)))
+	if offset < 0 {
+		offset += total
+	}
+	idx := 0
+	remaining := dashes[0]
+	for offset > 0 {
+		if offset < remaining {
+			remaining -= offset
+			break
+		}
+		offset -= remaining
+		idx = (idx + 1) % len(dashes)
+		remaining = dashes[idx]
+	}
+
+	on := (idx % 2 == 0)
+	startedOn := on
+	ret := make([][]Vec3, 0)
+	current := make([]Vec3, 0)
+	if on {
+		current = append(current, points[0])
+	}
+	for i := 0; i < len(points) - 1; i++ {
+		a := points[i]
+		b := points[i+1]
+		segLen := b.Sub(a).Len()
+		pos := float32(0)
+		for segLen - pos > remaining {
+			pos += remaining
+			t := pos / segLen
+			p := a.Add(b.Sub(a).Scaled(t, t, t))
+			if on {
+				ret = append(ret, append(current, p))
+				current = make([]Vec3, 0)
+			} else {
+				current = append(current, p)
+			}
+			on = !on
+			idx = (idx + 1) % len(dashes)
+			remaining = dashes[idx]
+		}
+		remaining -= segLen - pos
+		if on {
+			current = append(current, b)
+		}
+	}
+
+	if on && len(current) >= 2 {
+		if closed && startedOn && len(ret) > 0 {
+			// The last dash runs into the first one through the start point
+			ret[0] = append(current, ret[0][1:]...)
+		} else {
+			ret = append(ret, current)
+		}
+	}
+	return ret
+}
+
+type stroker struct {
+	g *GeomDraw
+	hw float32 // Half of the line width
+	miterLimit float32
+	positions []Vec3
+	inds []uint32
+}
+
+func (s *stroker) add(p Vec2, z float32) uint32 {
+	s.positions = append(s.positions, Vec3{p[0], p[1], z})
+	return uint32(len(s.positions) - 1)
+}
+
+// Adds a triangle fan around the center
+func (s *stroker) fan(center uint32, ring []uint32) {
+	for i := 0; i < len(ring) - 1; i++ {
+		s.inds = append(s.inds, center, ring[i], ring[i+1])
+	}
+}
+
+// Adds the points of an arc, not including the start and end points
+func (s *stroker) arc(center Vec2, z float32, start, sweep float32, ring []uint32) []uint32 {
+	divisions := s.g.Divisions
+	if divisions < 8 {
+		divisions = 8
+	}
+	steps := int(math.Ceil(math.Abs(float64(sweep)) / (2 * math.Pi) * float64(divisions)))
+	for i := 1; i < steps; i++ {
+		angle := float64(start + (sweep * float32(i) / float32(steps)))
+		ring = append(ring, s.add(center.Add(Vec2{float32(math.Cos(angle)), float32(math.Sin(angle))}.Scaled(s.hw)), z))
+	}
+	return ring
+}
+
+func (s *stroker) stroke(points []Vec3, closed bool) {
+	n := len(points)
+	if n < 2 { return }
+
+	numSegs := n - 1
+	if closed {
+		numSegs = n
+	}
+
+	dirs := make([]Vec2, numSegs)
+	normals := make([]Vec2, numSegs)
+	lengths := make([]float32, numSegs)
+	for i := range dirs {
+		d := points[(i + 1) % n].Vec2().Sub(points[i].Vec2())
+		lengths[i] = d.Len()
+		dirs[i] = d.Scaled(1 / lengths[i])
+		normals[i] = Vec2{-dirs[i][1], dirs[i][0]} // Points to the left of the line
+	}
+
+	// The left and right vertices at the start and end of every segment
+	startL := make([]uint32, numSegs)
+	startR := make([]uint32, numSegs)
+	endL := make([]uint32, numSegs)
+	endR := make([]uint32, numSegs)
+
+	if closed {
+		for i := 0; i < n; i++ {
+			in := (i + n - 1) % n
+			s.join(points[i], in, i, dirs, normals, lengths, endL, endR, startL, startR)
+		}
+	} else {
+		for i := 1; i < n - 1; i++ {
+			s.join(points[i], i - 1, i, dirs, normals, lengths, endL, endR, startL, startR)
+		}
+		startR[0], startL[0] = s.cap(points[0], dirs[0].Scaled(-1))
+		endL[numSegs-1], endR[numSegs-1] = s.cap(points[n-1], dirs[numSegs-1])
+	}
+
+	for i := 0; i < numSegs; i++ {
+		s.inds = append(s.inds,
+			startL[i], startR[i], endR[i],
+			startL[i], endR[i], endL[i],
+		)
+	}
+}
+
+// Adds the cap at the end of a line, where dir points out of the line. Returns the left and right vertices relative to dir
+func (s *stroker) cap(point Vec3, dir Vec2) (uint32, uint32) {
+	p := point.Vec2()
+	z := point[2]
+	normal := Vec2{-dir[1], dir[0]}
+
+	if s.g.Cap == CapSquare {
+		p = p.Add(dir.Scaled(s.hw))
+	}
+	left := s.add(p.Add(normal.Scaled(s.hw)), z)
+	right := s.add(p.Sub(normal.Scaled(s.hw)), z)
+
+	if s.g.Cap == CapRound {
+		// Sweep from the right side, around the tip, to the left side
+		start := float32(math.Atan2(float64(-normal[1]), float64(-normal[0])))
+		ring := []uint32{right}
+		ring = s.arc(p, z, start, math.Pi, ring)
+		ring = append(ring, left)
+		s.fan(s.add(p, z), ring)
+	}
+	return left, right
+}
+
+// Joins the incoming segment to the outgoing segment at the point
+func (s *stroker) join(point Vec3, in, out int, dirs, normals []Vec2, lengths []float32, endL, endR, startL, startR []uint32) {
+	p := point.Vec2()
+	z := point[2]
+	dIn, dOut := dirs[in], dirs[out]
+	nIn, nOut := normals[in], normals[out]
+
+	turn := (dIn[0] * dOut[1]) - (dIn[1] * dOut[0])
+	dot := (dIn[0] * dOut[0]) + (dIn[1] * dOut[1])
+	if math.Abs(float64(turn)) < 1e-6 && dot > 0 {
+		// Straight, the segments can share their vertices
+		l := s.add(p.Add(nIn.Scaled(s.hw)), z)
+		r := s.add(p.Sub(nIn.Scaled(s.hw)), z)
+		endL[in], endR[in] = l, r
+		startL[out], startR[out] = l, r
+		return
+	}
+
+	// The inner side of the corner is the side the line turns towards
+	side := float32(1)
+	if turn < 0 {
+		side = -1
+	}
+
+	// The offset from the point to where the offset edges of both segments intersect
+	var miter Vec2
+	miterOk := false
+	innerOk := false
+	nDot := (nIn[0] * nOut[0]) + (nIn[1] * nOut[1])
+	if 1 + nDot > 1e-6 {
+		miter = nIn.Add(nOut).Scaled(s.hw / (1 + nDot))
+		miterOk = (miter.Len() / s.hw) <= s.miterLimit
+
+		// The inner intersection can only be shared if it doesn't run past the middle of either segment
+		along := float32(math.Abs(float64((miter[0] * dIn[0]) + (miter[1] * dIn[1]))))
+		innerOk = along <= lengths[in] / 2 && along <= lengths[out] / 2
+	}
+
+	var innerIn, innerOut uint32
+	if innerOk {
+		innerIn = s.add(p.Add(miter.Scaled(side)), z)
+		innerOut = innerIn
+	} else {
+		innerIn = s.add(p.Add(nIn.Scaled(side * s.hw)), z)
+		innerOut = s.add(p.Add(nOut.Scaled(side * s.hw)), z)
+	}
+	outerIn := s.add(p.Sub(nIn.Scaled(side * s.hw)), z)
+	outerOut := s.add(p.Sub(nOut.Scaled(side * s.hw)), z)
+
+	if side > 0 {
+		endL[in], endR[in] = innerIn, outerIn
+		startL[out], startR[out] = innerOut, outerOut
+	} else {
+		endL[in], endR[in] = outerIn, innerIn
+		startL[out], startR[out] = outerOut, innerOut
+	}
+
+	// Fill the wedge on the outer side of the corner
+	ring := make([]uint32, 0)
+	if innerOk {
+		ring = append(ring, innerIn)
+	}
+	ring = append(ring, outerIn)
+	switch s.g.Join {
+	case JoinMiter:
+		if miterOk {
+			ring = append(ring, s.add(p.Sub(miter.Scaled(side)), z))
+		}
+	case JoinRound:
+		outer := nIn.Scaled(-side)
+		start := float32(math.Atan2(float64(outer[1]), float64(outer[0])))
+		sweep := float32(math.Atan2(float64(turn), float64(dot)))
+		ring = s.arc(p, z, start, sweep, ring)
+	}
+	ring = append(ring, outerOut)
+	if innerOk {
+		ring = append(ring, innerOut)
+	}
+	s.fan(s.add(p, z), ring)
+}
@@ -0,0 +1,70 @@
+package glitch
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStrokeCaps(t *testing.T) {
+	line := []Vec3{{0, 0, 0}, {5, 0, 0}, {10, 0, 0}}
+
+	g := NewGeomDraw()
+	mesh := g.LineStrip(line, 2)
+	checkArea(t, triangleArea(mesh.positions, mesh.indices), 20)
+
+	g.Cap = CapSquare
+	mesh = g.LineStrip(line, 2)
+	checkArea(t, triangleArea(mesh.positions, mesh.indices), 24)
+	if mesh.Bounds().Min[0] != -1 || mesh.Bounds().Max[0] != 11 {
+		t.Fatalf("Unexpected bounds: %v", mesh.Bounds())
+	}
+
+	g.Cap = CapRound
+	g.Divisions = 10000
+	mesh = g.LineStrip(line, 2)
+	if math.Abs(float64(triangleArea(mesh.positions, mesh.indices) - (20 + math.Pi))) > 0.01 {
+		t.Fatalf("Unexpected round cap area: %v", triangleArea(mesh.positions, mesh.indices))
+	}
+}
+
+func TestStrokeJoins(t *testing.T) {
+	square := []Vec3{{0, 0, 0}, {10, 0, 0}, {10, 10, 0}, {0, 10, 0}}
+
+	// Any overlapping triangles would add to the total area
+	g := NewGeomDraw()
+	mesh := g.Polygon(square, 2)
+	checkArea(t, triangleArea(mesh.positions, mesh.indices), (12 * 12) - (8 * 8))
+
+	g.Join = JoinBevel
+	mesh = g.Polygon(square, 2)
+	checkArea(t, triangleArea(mesh.positions, mesh.indices), (12 * 12) - (8 * 8) - 4 * 0.5)
+
+	g.Join = JoinRound
+	g.Divisions = 10000
+	mesh = g.Polygon(square, 2)
+	if math.Abs(float64(triangleArea(mesh.positions, mesh.indices) - float32(80 - 4 + math.Pi))) > 0.01 {
+		t.Fatalf("Unexpected round join area: %v", triangleArea(mesh.positions, mesh.indices))
+	}
+
+	// The miter of a right angle is sqrt(2) times the width, so this bevels
+	g.Join = JoinMiter
+	g.MiterLimit = 1.2
+	mesh = g.Polygon(square, 2)
+	checkArea(t, triangleArea(mesh.positions, mesh.indices), (12 * 12) - (8 * 8) - 4 * 0.5)
+}
+
+func TestStrokeDashes(t *testing.T) {
+	g := NewGeomDraw()
+	g.Dashes = []float32{2, 2}
+	mesh := g.LineStrip([]Vec3{{0, 0, 0}, {10, 0, 0}}, 2)
+	checkArea(t, triangleArea(mesh.positions, mesh.indices), 3 * 2 * 2)
+
+	dashes := dashPath([]Vec3{{0, 0, 0}, {4, 0, 0}, {4, 4, 0}}, false, []float32{2, 1}, 0)
+	if len(dashes) != 3 {
+		t.Fatalf("Expected 3 dashes, got %d: %v", len(dashes), dashes)
+	}
+	// The second dash turns the corner
+	if len(dashes[1]) != 3 || dashes[1][1] != (Vec3{4, 0, 0}) || dashes[1][2] != (Vec3{4, 1, 0}) {
+		t.Fatalf("Unexpected dash: %v", dashes[1])
+	}
+}
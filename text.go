@@ -72,7 +72,8 @@ func NewAtlas(face font.Face, runes []rune, smooth bool) *Atlas {
 	fixedSize := fixed.I(size)
 	fSize := float32(size)
 
-	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	// Glyphs are only coverage, so they are stored in a single channel texture (See FormatR8)
+	img := image.NewAlpha(image.Rect(0, 0, size, size))
 	// draw.Draw(img, img.Bounds(), image.NewUniform(color.Alpha{0}), image.ZP, draw.Src)
 	// Note: In case you want to see the boundary of each rune, uncomment this
 	// draw.Draw(img, img.Bounds(), image.NewUniform(color.Black), image.ZP, draw.Src)
@@ -140,7 +141,9 @@ func NewAtlas(face font.Face, runes []rune, smooth bool) *Atlas {
 	// png.Encode(outputFile, img)
 	// outputFile.Close()
 
-	atlas.texture = NewTexture(img, smooth)
+	config := SmoothTextureConfig(smooth)
+	config.Format = FormatR8
+	atlas.texture = NewTextureExt(img, config)
 	// fmt.Println("TextAtlas: ", atlas.texture.width, atlas.texture.height)
 	return atlas
 }
@@ -156,11 +159,15 @@ func (a *Atlas) StringVerts(orig *Vec2, dot *Vec2, text string, color RGBA, scal
 	// maxAscent := float32(0) // Tracks the maximum y point of the text block
 
 	initialDot := *dot
+	maxX := dot[0] // The end of the longest line
 
 	mesh := NewMesh()
 	for _,r := range text {
 		// If the rune is a newline, then we need to reset the dot for the next line
 		if r == '\n' {
+			if dot[0] > maxX {
+				maxX = dot[0]
+			}
 			dot[1] -= a.LineHeight()
 			dot[0] = orig[0]
 			continue
@@ -192,10 +199,14 @@ func (a *Atlas) StringVerts(orig *Vec2, dot *Vec2, text string, color RGBA, scal
 	// 	dot[0], // TODO - this is wrong if because this is the length of the last line, we need the length of the longest line
 	// 	dot[1] - (2 * fixedToFloat(a.descent)))
 
+	if dot[0] > maxX {
+		maxX = dot[0]
+	}
+
 	// TODO - idk what I'm doing here, but it seems to work. Man text rendering is hard.
 	bounds := R(initialDot[0],
 		initialDot[1] - (fixedToFloat(a.ascent)),
-		dot[0], // TODO - this is wrong if because this is the length of the last line, we need the length of the longest line
+		maxX,
 		dot[1] - (fixedToFloat(a.descent))).
 			Norm().
 			Moved(Vec2{0, fixedToFloat(a.ascent)})
@@ -1,11 +1,14 @@
 package glitch
 
 import (
-	// "fmt"
+	"fmt"
+	"os"
 	"image"
+	"image/png"
 	"image/draw"
 	"image/color"
 	"runtime"
+	"unsafe"
 	"github.com/faiface/mainthread"
 	"github.com/unitoftime/gl"
 )
@@ -31,6 +34,238 @@ func WhiteTexture() *Texture {
 type Texture struct {
 	texture gl.Texture
 	width, height int
+	config TextureConfig
+	compressed bool // Compressed textures can't be written to or have mipmaps generated (See NewCompressedTexture)
+}
+
+type TextureFilter uint8
+const (
+	FilterNearest TextureFilter = iota
+	FilterLinear
+)
+
+type TextureWrap uint8
+const (
+	WrapClamp TextureWrap = iota // Clamp coordinates to the edge pixels
+	WrapRepeat // Tile the texture
+	WrapMirror // Tile the texture, flipping every other tile
+)
+
+// The format that the texture is stored as on the GPU
+type TextureFormat uint8
+const (
+	FormatRGBA8 TextureFormat = iota // 8 bits per channel RGBA
+	FormatR8 // A single 8 bit channel, sampled as (r, r, r, r). Images are converted using their alpha channel (or their gray value if they are an *image.Gray). WebGL can't swizzle channels, so there the data is expanded and stored as RGBA8 (which samples the same, but doesn't save memory)
+	FormatRGBA16F // 16 bit float per channel RGBA
+	FormatRGBA32F // 32 bit float per channel RGBA
+	FormatSRGBA8 // 8 bits per channel RGBA, stored in the sRGB color space and converted to linear when sampled
+)
+
+// These are part of GL 3.0 / GLES 3.0 but aren't exported by the gl package
+const (
+	glRED = 0x1903
+	glR8 = 0x8229
+	glRGBA8 = 0x8058
+	glRGBA16F = 0x881A
+	glRGBA32F = 0x8814
+	glSRGB8_ALPHA8 = 0x8C43
+	glTEXTURE_SWIZZLE_R = 0x8E42
+	glTEXTURE_SWIZZLE_G = 0x8E43
+	glTEXTURE_SWIZZLE_B = 0x8E44
+	glTEXTURE_SWIZZLE_A = 0x8E45
+)
+
+// Returns the internal format, pixel format, and pixel type used to upload this format
+func (f TextureFormat) glFormats() (gl.Enum, gl.Enum, gl.Enum) {
+	switch f {
+	case FormatR8:
+		if !textureSwizzleSupported {
+			return gl.RGBA, gl.RGBA, gl.UNSIGNED_BYTE // See expandR8
+		}
+		return glR8, glRED, gl.UNSIGNED_BYTE
+	case FormatRGBA16F:
+		return glRGBA16F, gl.RGBA, gl.FLOAT // Note: We upload float32s and let the driver convert them to halfs
+	case FormatRGBA32F:
+		return glRGBA32F, gl.RGBA, gl.FLOAT
+	case FormatSRGBA8:
+		return glSRGB8_ALPHA8, gl.RGBA, gl.UNSIGNED_BYTE
+	default:
+		return gl.RGBA, gl.RGBA, gl.UNSIGNED_BYTE
+	}
+}
+
+// Returns the sized internal format used for renderbuffers of this format, which GLES3 and WebGL2 require (unlike textures, which accept unsized formats)
+func (f TextureFormat) renderbufferFormat() gl.Enum {
+	switch f {
+	case FormatR8:
+		if !textureSwizzleSupported {
+			return glRGBA8 // Matches the texture it resolves into (See glFormats)
+		}
+		return glR8
+	case FormatRGBA16F:
+		return glRGBA16F
+	case FormatRGBA32F:
+		return glRGBA32F
+	case FormatSRGBA8:
+		return glSRGB8_ALPHA8
+	default:
+		return glRGBA8
+	}
+}
+
+// Returns the number of bytes per pixel of the data uploaded for this format
+func (f TextureFormat) PixelSize() int {
+	switch f {
+	case FormatR8:
+		return 1
+	case FormatRGBA16F, FormatRGBA32F:
+		return 4 * 4 // Uploaded as float32
+	default:
+		return 4
+	}
+}
+
+// Returns true if the format is stored as floats
+func (f TextureFormat) IsFloat() bool {
+	return f == FormatRGBA16F || f == FormatRGBA32F
+}
+
+// Converts an image into the pixel data expected by this format
+func (f TextureFormat) imagePixels(img image.Image) []byte {
+	switch f {
+	case FormatR8:
+		if gray, ok := img.(*image.Gray); ok && gray.Stride == gray.Bounds().Dx() {
+			return gray.Pix
+		}
+		if alpha, ok := img.(*image.Alpha); ok && alpha.Stride == alpha.Bounds().Dx() {
+			return alpha.Pix
+		}
+		alpha := image.NewAlpha(img.Bounds())
+		draw.Draw(alpha, alpha.Bounds(), img, img.Bounds().Min, draw.Src)
+		return alpha.Pix
+	case FormatRGBA16F, FormatRGBA32F:
+		rgba := toRgba(img)
+		floats := make([]float32, len(rgba.Pix))
+		for i := range rgba.Pix {
+			floats[i] = float32(rgba.Pix[i]) / 255.0
+		}
+		return floatBytes(floats)
+	default:
+		return toRgba(img).Pix
+	}
+}
+
+// Converts single channel pixels into (r, r, r, r) RGBA pixels, for platforms that store FormatR8 as RGBA8 (See textureSwizzleSupported)
+func expandR8(pixels []byte) []byte {
+	rgba := make([]byte, len(pixels) * 4)
+	for i, r := range pixels {
+		rgba[(i * 4) + 0] = r
+		rgba[(i * 4) + 1] = r
+		rgba[(i * 4) + 2] = r
+		rgba[(i * 4) + 3] = r
+	}
+	return rgba
+}
+
+// Float color attachments need EXT_color_buffer_float on WebGL2 (it's core on desktop GL), which has to be enabled before rendering or reading from them
+// Note: Must be called on the mainthread
+func floatRenderingSupported() bool {
+	return enableExtension("EXT_color_buffer_float")
+}
+
+// Reinterprets a float32 slice as a byte slice (without copying)
+func floatBytes(floats []float32) []byte {
+	if len(floats) == 0 { return nil }
+	return unsafe.Slice((*byte)(unsafe.Pointer(&floats[0])), len(floats) * 4)
+}
+
+// Note: The zero value is a nearest filtered, edge clamped, RGBA8 texture with no mipmaps
+type TextureConfig struct {
+	Format TextureFormat
+	MinFilter TextureFilter // Filter used when the texture is scaled down
+	MagFilter TextureFilter // Filter used when the texture is scaled up
+	Mipmap bool // If true, mipmaps are generated and used when the texture is scaled down
+	MipmapFilter TextureFilter // Filter used to blend between mipmap levels
+	WrapS, WrapT TextureWrap // How texture coordinates outside of [0, 1] are handled on the horizontal and vertical axis
+	Anisotropy float32 // The max anisotropic filtering samples (clamped to what the driver supports). Values <= 1 disable it
+}
+
+// Returns a config with both filters set to linear (if smooth) or nearest (if not)
+func SmoothTextureConfig(smooth bool) TextureConfig {
+	if smooth {
+		return TextureConfig{
+			MinFilter: FilterLinear,
+			MagFilter: FilterLinear,
+		}
+	}
+	return TextureConfig{
+		MinFilter: FilterNearest,
+		MagFilter: FilterNearest,
+	}
+}
+
+// These are part of EXT_texture_filter_anisotropic (core in GL 4.6) and aren't exported by the gl package
+const (
+	glTEXTURE_MAX_ANISOTROPY = 0x84FE
+	glMAX_TEXTURE_MAX_ANISOTROPY = 0x84FF
+)
+
+// The max anisotropy supported by the driver, or 0 if anisotropic filtering is unsupported
+// Note: Must be called on the mainthread
+var maxAnisotropy float32 = -1
+func getMaxAnisotropy() float32 {
+	if maxAnisotropy >= 0 { return maxAnisotropy }
+
+	if !enableExtension("EXT_texture_filter_anisotropic") {
+		maxAnisotropy = 0
+		return maxAnisotropy
+	}
+
+	gl.GetError() // Clear any previous error
+	maxAnisotropy = getFloatParameter(glMAX_TEXTURE_MAX_ANISOTROPY)
+	if gl.GetError() != gl.NO_ERROR {
+		maxAnisotropy = 0
+	}
+	return maxAnisotropy
+}
+
+func (f TextureFilter) glFilter() int {
+	if f == FilterLinear {
+		return gl.LINEAR
+	}
+	return gl.NEAREST
+}
+
+func (w TextureWrap) glWrap() int {
+	switch w {
+	case WrapRepeat:
+		return gl.REPEAT
+	case WrapMirror:
+		return gl.MIRRORED_REPEAT
+	default:
+		// TODO - webgl doesn't support CLAMP_TO_BORDER
+		// GL_CLAMP_TO_EDGE: The coordinate will simply be clamped between 0 and 1.
+		// GL_CLAMP_TO_BORDER: The coordinates that fall outside the range will be given a specified border color.
+		return gl.CLAMP_TO_EDGE
+	}
+}
+
+func (c TextureConfig) glMinFilter() int {
+	if !c.Mipmap {
+		return c.MinFilter.glFilter()
+	}
+
+	if c.MinFilter == FilterLinear {
+		if c.MipmapFilter == FilterLinear {
+			return gl.LINEAR_MIPMAP_LINEAR
+		}
+		return gl.LINEAR_MIPMAP_NEAREST
+	}
+
+	if c.MipmapFilter == FilterLinear {
+		return gl.NEAREST_MIPMAP_LINEAR
+	}
+	return gl.NEAREST_MIPMAP_NEAREST
 }
 
 func toRgba(img image.Image) *image.RGBA {
@@ -40,42 +275,58 @@ func toRgba(img image.Image) *image.RGBA {
 }
 
 func NewTexture(img image.Image, smooth bool) *Texture {
-	// We can only use RGBA images right now.
-	rgba := toRgba(img)
+	return NewTextureExt(img, SmoothTextureConfig(smooth))
+}
+
+func NewTextureExt(img image.Image, config TextureConfig) *Texture {
+	pixels := config.Format.imagePixels(img)
+	return newTexture(img.Bounds().Dx(), img.Bounds().Dy(), pixels, config)
+}
+
+// Creates a float texture from RGBA float data (4 floats per pixel). Config.Format must be a float format
+func NewTextureFloat(width, height int, pixels []float32, config TextureConfig) *Texture {
+	if !config.Format.IsFloat() {
+		panic("NewTextureFloat: texture format must be a float format")
+	}
+	if len(pixels) != width * height * 4 {
+		panic("NewTextureFloat: wrong number of pixels")
+	}
+	return newTexture(width, height, floatBytes(pixels), config)
+}
 
-	width := rgba.Bounds().Dx()
-	height := rgba.Bounds().Dy()
-	pixels := rgba.Pix
+func newTexture(width, height int, pixels []byte, config TextureConfig) *Texture {
 	t := &Texture{
 		width: width,
 		height: height,
+		config: config,
+	}
+
+	internalFormat, format, ty := config.Format.glFormats()
+	singleChannel := config.Format == FormatR8 && textureSwizzleSupported
+	if config.Format == FormatR8 && !textureSwizzleSupported && pixels != nil {
+		pixels = expandR8(pixels)
 	}
 
 	mainthread.Call(func() {
 		t.texture = gl.CreateTexture()
 		gl.BindTexture(gl.TEXTURE_2D, t.texture)
 
-		gl.TexImage2D(gl.TEXTURE_2D, 0, width, height, gl.RGBA, gl.UNSIGNED_BYTE, pixels)
-
-		// TODO - webgl doesn't support CLAMP_TO_BORDER
-		// GL_CLAMP_TO_EDGE: The coordinate will simply be clamped between 0 and 1.
-		// GL_CLAMP_TO_BORDER: The coordinates that fall outside the range will be given a specified border color.
-
-		// TODO - Do I need this all the time? Probably not, especially when I'm doing texture atlases
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
-
-		// gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
-		// gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.REPEAT)
+		if singleChannel {
+			// Single byte rows aren't guaranteed to be 4 byte aligned
+			gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+		}
+		texImage2D(internalFormat, width, height, format, ty, pixels)
+		gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
 
-		// TODO - pass smooth in as a parameter
-		if smooth {
-			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-		} else {
-			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
-			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+		if singleChannel {
+			// Sample the single channel as (r, r, r, r), which matches a premultiplied white RGBA texture
+			gl.TexParameteri(gl.TEXTURE_2D, glTEXTURE_SWIZZLE_R, glRED)
+			gl.TexParameteri(gl.TEXTURE_2D, glTEXTURE_SWIZZLE_G, glRED)
+			gl.TexParameteri(gl.TEXTURE_2D, glTEXTURE_SWIZZLE_B, glRED)
+			gl.TexParameteri(gl.TEXTURE_2D, glTEXTURE_SWIZZLE_A, glRED)
 		}
+
+		t.applyConfig()
 	})
 
 	runtime.SetFinalizer(t, (*Texture).delete)
@@ -83,6 +334,44 @@ func NewTexture(img image.Image, smooth bool) *Texture {
 	return t
 }
 
+// Sets the texture parameters from the texture's config and builds mipmaps if needed
+// Note: Must be called on the mainthread with the texture bound
+func (t *Texture) applyConfig() {
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, t.config.WrapS.glWrap())
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, t.config.WrapT.glWrap())
+
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, t.config.glMinFilter())
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, t.config.MagFilter.glFilter())
+
+	if t.config.Anisotropy > 1 {
+		max := getMaxAnisotropy()
+		if max > 0 {
+			anisotropy := t.config.Anisotropy
+			if anisotropy > max {
+				anisotropy = max
+			}
+			gl.TexParameterf(gl.TEXTURE_2D, glTEXTURE_MAX_ANISOTROPY, anisotropy)
+		}
+	}
+
+	if t.config.Mipmap && !t.compressed {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
+}
+
+// Changes the texture's filtering, wrapping, and mipmapping
+func (t *Texture) SetConfig(config TextureConfig) {
+	t.config = config
+	t.Bind(0)
+	mainthread.Call(func() {
+		t.applyConfig()
+	})
+}
+
+func (t *Texture) Config() TextureConfig {
+	return t.config
+}
+
 // Sets the texture to be this image.
 // Texture size must match img size or this will panic!
 // TODO - Should I just try and set it? or do nothing?
@@ -93,48 +382,164 @@ func (t *Texture) SetImage(img image.Image) {
 		panic("SetImage: img bounds are not equal to texture bounds!")
 	}
 
-	// // TODO - skip this if already an NRGBA
-	// nrgba := image.NewNRGBA(img.Bounds())
-	// draw.Draw(nrgba, nrgba.Bounds(), img, img.Bounds().Min, draw.Src)
-
-	// pixels := nrgba.Pix
-
-	rgba := toRgba(img)
-	pixels := rgba.Pix
+	pixels := t.config.Format.imagePixels(img)
 	t.SetPixels(0, 0, t.width, t.height, pixels)
 }
 
 // Sets the pixels of a section of a texture
+// The pixel data must be in the layout of the texture's format (See TextureFormat.PixelSize)
 func (t *Texture) SetPixels(x, y, w, h int, pixels []uint8) {
-	if len(pixels) != w*h*4 {
+	if t.compressed {
+		panic("set pixels: can't set the pixels of a compressed texture")
+	}
+	if len(pixels) != w*h*t.config.Format.PixelSize() {
 		panic("set pixels: wrong number of pixels")
 	}
 	t.Bind(0)
 
+	_, format, ty := t.config.Format.glFormats()
+	singleChannel := t.config.Format == FormatR8 && textureSwizzleSupported
+	if t.config.Format == FormatR8 && !textureSwizzleSupported {
+		pixels = expandR8(pixels)
+	}
+
 	mainthread.Call(func() {
-		gl.TexSubImage2D(
-			gl.TEXTURE_2D,
-			0,
-			x,
-			y,
-			w,
-			h,
-			gl.RGBA,
-			gl.UNSIGNED_BYTE,
-			pixels,
-		)
+		if singleChannel {
+			gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+		}
+
+		texSubImage2D(x, y, w, h, format, ty, pixels)
+		gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+
+		if t.config.Mipmap {
+			gl.GenerateMipmap(gl.TEXTURE_2D)
+		}
 	})
 }
 
+// Sets the pixels of a section of a float texture (4 floats per pixel)
+func (t *Texture) SetPixelsFloat(x, y, w, h int, pixels []float32) {
+	if !t.config.Format.IsFloat() {
+		panic("set pixels float: texture format must be a float format")
+	}
+	t.SetPixels(x, y, w, h, floatBytes(pixels))
+}
+
+// Reads the texture back from the GPU. This works by attaching the texture to a temporary framebuffer and reading its pixels, which is the only way to read textures in GLES and WebGL.
+// Rows are returned in the order they are stored in the texture, which is the same order that they were uploaded in (Note: Frames render bottom-up, see Frame.Image).
+// Single channel textures are expanded to (r, r, r, r) and float textures are clamped to [0, 1]
+func (t *Texture) Image() (*image.RGBA, error) {
+	img := image.NewRGBA(image.Rect(0, 0, t.width, t.height))
+	if t.width <= 0 || t.height <= 0 {
+		return img, nil
+	}
+
+	var floats []float32
+	if t.config.Format.IsFloat() {
+		floats = make([]float32, t.width * t.height * 4)
+	}
+
+	var status gl.Enum
+	mainthread.Call(func() {
+		prevFbo := gl.GetBoundFramebuffer()
+		fbo := gl.CreateFramebuffer()
+		gl.BindFramebuffer(gl.FRAMEBUFFER, fbo)
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, t.texture, 0)
+
+		if floats != nil {
+			floatRenderingSupported()
+		}
+		status = gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+		if status == gl.FRAMEBUFFER_COMPLETE {
+			if floats != nil {
+				readPixels(floatBytes(floats), 0, 0, t.width, t.height, gl.RGBA, gl.FLOAT)
+			} else {
+				readPixels(img.Pix, 0, 0, t.width, t.height, gl.RGBA, gl.UNSIGNED_BYTE)
+			}
+		}
+
+		gl.BindFramebuffer(gl.FRAMEBUFFER, prevFbo)
+		gl.DeleteFramebuffer(fbo)
+	})
+
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		return nil, fmt.Errorf("Texture.Image: texture can't be attached to a framebuffer (status: 0x%x)", status)
+	}
+
+	switch {
+	case floats != nil:
+		for i := range floats {
+			v := floats[i]
+			if v < 0 { v = 0 }
+			if v > 1 { v = 1 }
+			img.Pix[i] = uint8(v * 255 + 0.5)
+		}
+	case t.config.Format == FormatR8:
+		// Read back as (r, 0, 0, 1) (Or (r, r, r, r) where it's stored as RGBA8, which this leaves unchanged)
+		for i := 0; i < len(img.Pix); i += 4 {
+			r := img.Pix[i]
+			img.Pix[i+1] = r
+			img.Pix[i+2] = r
+			img.Pix[i+3] = r
+		}
+	}
+
+	return img, nil
+}
+
+// Reads the texture back from the GPU and writes it to a PNG file
+func (t *Texture) SavePNG(path string) error {
+	img, err := t.Image()
+	if err != nil {
+		return err
+	}
+	return savePNG(path, img)
+}
+
+func savePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = png.Encode(file, img)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
 func (t *Texture) Bounds() Rect {
 	return R(0, 0, float32(t.width), float32(t.height))
 }
 
 func (t *Texture) Bind(position int) {
 	mainthread.Call(func() {
-		gl.ActiveTexture(gl.TEXTURE0);
-		// gl.ActiveTexture(gl.TEXTURE0 + position); // TODO - include position
+		gl.ActiveTexture(gl.Enum(gl.TEXTURE0 + position))
 		gl.BindTexture(gl.TEXTURE_2D, t.texture)
+		if position != 0 {
+			// Texture creation and uploads bind to whatever unit is active, so unit 0 is left active to keep them from replacing this binding
+			gl.ActiveTexture(gl.TEXTURE0)
+		}
+	})
+}
+
+// Reallocates the texture's storage at a new size. The contents are lost
+func (t *Texture) resize(width, height int) {
+	if t.compressed {
+		panic("resize: can't resize a compressed texture")
+	}
+	t.width = width
+	t.height = height
+
+	internalFormat, format, ty := t.config.Format.glFormats()
+	mainthread.Call(func() {
+		gl.BindTexture(gl.TEXTURE_2D, t.texture)
+		gl.TexImage2DFull(gl.TEXTURE_2D, 0, internalFormat, width, height, format, ty, nil)
+		if t.config.Mipmap {
+			gl.GenerateMipmap(gl.TEXTURE_2D)
+		}
 	})
 }
 
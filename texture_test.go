@@ -0,0 +1,28 @@
+package glitch
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestR8Pixels(t *testing.T) {
+	img := image.NewAlpha(image.Rect(0, 0, 2, 1))
+	img.SetAlpha(1, 0, color.Alpha{200})
+	if pix := FormatR8.imagePixels(img); !bytes.Equal(pix, []byte{0, 200}) {
+		t.Fatalf("Unexpected R8 pixels: %v", pix)
+	}
+
+	// RGBA images use their alpha channel
+	rgba := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	rgba.SetRGBA(0, 0, color.RGBA{50, 50, 50, 50})
+	if pix := FormatR8.imagePixels(rgba); !bytes.Equal(pix, []byte{50, 0}) {
+		t.Fatalf("Unexpected R8 pixels: %v", pix)
+	}
+
+	// Where R8 is stored as RGBA8, it needs to sample the same as the swizzled single channel
+	if pix := expandR8([]byte{0, 200}); !bytes.Equal(pix, []byte{0, 0, 0, 0, 200, 200, 200, 200}) {
+		t.Fatalf("Unexpected expanded pixels: %v", pix)
+	}
+}
@@ -0,0 +1,212 @@
+package glitch
+
+import (
+	"sort"
+)
+
+// Triangulates a simple polygon (which may be concave) with ear clipping. Holes must be simple polygons inside of the outline which don't touch each other.
+// The returned indices reference the outline's points followed by the points of each hole, in the order they were passed in.
+// Points are treated as 2D (z is ignored) and can be in either winding order
+func Triangulate(outline []Vec3, holes ...[]Vec3) []uint32 {
+	if len(outline) < 3 { return []uint32{} }
+
+	verts := make([]Vec2, 0, len(outline))
+	for _, p := range outline {
+		verts = append(verts, p.Vec2())
+	}
+
+	ring := make([]int, len(outline))
+	for i := range ring {
+		ring[i] = i
+	}
+	if ringArea(verts, ring) < 0 {
+		reverseInts(ring)
+	}
+
+	holeRings := make([][]int, 0, len(holes))
+	for _, hole := range holes {
+		if len(hole) < 3 { continue }
+		hRing := make([]int, len(hole))
+		for i := range hole {
+			hRing[i] = len(verts)
+			verts = append(verts, hole[i].Vec2())
+		}
+		if ringArea(verts, hRing) > 0 {
+			reverseInts(hRing) // Holes wind opposite to the outline
+		}
+		holeRings = append(holeRings, hRing)
+	}
+
+	// Holes are bridged in from right to left so that later bridges can't cross earlier ones
+	sort.Slice(holeRings, func(i, j int) bool {
+		return verts[holeRings[i][rightmost(verts, holeRings[i])]][0] > verts[holeRings[j][rightmost(verts, holeRings[j])]][0]
+	})
+	for _, hRing := range holeRings {
+		ring = bridgeHole(verts, ring, hRing)
+	}
+
+	return earClip(verts, ring)
+}
+
+// Returns twice the signed area of the ring. Positive is counter clockwise
+func ringArea(verts []Vec2, ring []int) float32 {
+	area := float32(0)
+	for i := range ring {
+		a := verts[ring[i]]
+		b := verts[ring[(i + 1) % len(ring)]]
+		area += (a[0] * b[1]) - (b[0] * a[1])
+	}
+	return area
+}
+
+func reverseInts(s []int) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// Returns the position in the ring of the vertex with the largest x
+func rightmost(verts []Vec2, ring []int) int {
+	best := 0
+	for i := range ring {
+		if verts[ring[i]][0] > verts[ring[best]][0] {
+			best = i
+		}
+	}
+	return best
+}
+
+// Returns the z component of the cross product of (b - a) and (c - b)
+func cross2(a, b, c Vec2) float32 {
+	return ((b[0] - a[0]) * (c[1] - b[1])) - ((b[1] - a[1]) * (c[0] - b[0]))
+}
+
+// Returns true if p is inside or on the edge of the counter clockwise triangle abc
+func inTriangle(p, a, b, c Vec2) bool {
+	return cross2(a, b, p) >= 0 && cross2(b, c, p) >= 0 && cross2(c, a, p) >= 0
+}
+
+// Joins a hole into the outline by cutting a zero width bridge from the hole's rightmost vertex to a vertex of the outline that it can see.
+// See: https://www.geometrictools.com/Documentation/TriangulationByEarClipping.pdf
+func bridgeHole(verts []Vec2, ring, hole []int) []int {
+	hStart := rightmost(verts, hole)
+	m := verts[hole[hStart]]
+
+	// Cast a ray to the right and find the closest edge that it hits
+	edge := -1
+	hitX := float32(0)
+	for i := range ring {
+		a := verts[ring[i]]
+		b := verts[ring[(i + 1) % len(ring)]]
+		if a[1] == b[1] { continue }
+		if (m[1] < a[1] && m[1] < b[1]) || (m[1] > a[1] && m[1] > b[1]) { continue }
+
+		x := a[0] + ((m[1] - a[1]) * (b[0] - a[0]) / (b[1] - a[1]))
+		if x < m[0] { continue }
+		if edge < 0 || x < hitX {
+			edge = i
+			hitX = x
+		}
+	}
+	if edge < 0 {
+		return ring // The hole isn't inside of the outline
+	}
+
+	// The bridge goes to the endpoint of the edge with the larger x, unless a reflex vertex is in the way
+	p := edge
+	if verts[ring[(edge + 1) % len(ring)]][0] > verts[ring[edge]][0] {
+		p = (edge + 1) % len(ring)
+	}
+	hit := Vec2{hitX, m[1]}
+	pVert := verts[ring[p]]
+	if pVert != hit {
+		// Order the triangle counter clockwise for the inside test
+		a, b, c := m, hit, pVert
+		if cross2(a, b, c) < 0 {
+			b, c = c, b
+		}
+
+		bestAngle := float32(-1)
+		bestDist := float32(0)
+		for i := range ring {
+			if i == p { continue }
+			v := verts[ring[i]]
+			prev := verts[ring[(i + len(ring) - 1) % len(ring)]]
+			next := verts[ring[(i + 1) % len(ring)]]
+			if cross2(prev, v, next) >= 0 { continue } // Only reflex vertices can block
+			if !inTriangle(v, a, b, c) { continue }
+
+			// Pick the blocking vertex closest in angle to the ray
+			d := v.Sub(m)
+			dist := d.Len()
+			if dist == 0 { continue }
+			cosAngle := d[0] / dist
+			if cosAngle > bestAngle || (cosAngle == bestAngle && dist < bestDist) {
+				bestAngle = cosAngle
+				bestDist = dist
+				p = i
+			}
+		}
+	}
+
+	// Splice: ..., P, M, hole..., M, P, ...
+	merged := make([]int, 0, len(ring) + len(hole) + 2)
+	merged = append(merged, ring[:p+1]...)
+	for i := 0; i <= len(hole); i++ {
+		merged = append(merged, hole[(hStart + i) % len(hole)])
+	}
+	merged = append(merged, ring[p])
+	merged = append(merged, ring[p+1:]...)
+	return merged
+}
+
+// Triangulates a counter clockwise ring by repeatedly cutting off ears
+func earClip(verts []Vec2, ring []int) []uint32 {
+	idx := make([]int, len(ring))
+	copy(idx, ring)
+
+	tris := make([]uint32, 0, 3 * (len(idx) - 2))
+	for len(idx) > 3 {
+		n := len(idx)
+		found := -1
+		for i := 0; i < n; i++ {
+			if isEar(verts, idx, i) {
+				found = i
+				break
+			}
+		}
+		if found < 0 {
+			// Degenerate or self intersecting input. Cut off any vertex so that we always finish
+			found = 0
+		}
+
+		prev := idx[(found + n - 1) % n]
+		next := idx[(found + 1) % n]
+		tris = append(tris, uint32(prev), uint32(idx[found]), uint32(next))
+		idx = append(idx[:found], idx[found+1:]...)
+	}
+	if len(idx) == 3 {
+		tris = append(tris, uint32(idx[0]), uint32(idx[1]), uint32(idx[2]))
+	}
+	return tris
+}
+
+func isEar(verts []Vec2, idx []int, i int) bool {
+	n := len(idx)
+	a := verts[idx[(i + n - 1) % n]]
+	b := verts[idx[i]]
+	c := verts[idx[(i + 1) % n]]
+	if cross2(a, b, c) <= 0 {
+		return false // Reflex or collinear
+	}
+
+	for j := 0; j < n; j++ {
+		v := verts[idx[j]]
+		// Skip the ear's own corners, including the duplicates made by hole bridges
+		if v == a || v == b || v == c { continue }
+		if inTriangle(v, a, b, c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package glitch
+
+import (
+	"math"
+	"testing"
+)
+
+// Returns the total area of the triangles
+func triangleArea(points []Vec3, inds []uint32) float32 {
+	area := float32(0)
+	for i := 0; i < len(inds); i += 3 {
+		a := points[inds[i]].Vec2()
+		b := points[inds[i+1]].Vec2()
+		c := points[inds[i+2]].Vec2()
+		area += float32(math.Abs(float64(cross2(a, b, c)))) / 2
+	}
+	return area
+}
+
+func checkArea(t *testing.T, got, want float32) {
+	t.Helper()
+	if math.Abs(float64(got - want)) > 0.001 {
+		t.Fatalf("Area: got %v, want %v", got, want)
+	}
+}
+
+func TestTriangulateConcave(t *testing.T) {
+	// An L shape, clockwise
+	points := []Vec3{
+		{0, 0, 0}, {0, 2, 0}, {1, 2, 0}, {1, 1, 0}, {2, 1, 0}, {2, 0, 0},
+	}
+	inds := Triangulate(points)
+	if len(inds) != 3 * 4 {
+		t.Fatalf("Expected 4 triangles, got %d", len(inds) / 3)
+	}
+	checkArea(t, triangleArea(points, inds), 3)
+}
+
+func TestTriangulateHoles(t *testing.T) {
+	outline := []Vec3{{0, 0, 0}, {10, 0, 0}, {10, 10, 0}, {0, 10, 0}}
+	holes := [][]Vec3{
+		{{1, 1, 0}, {3, 1, 0}, {3, 3, 0}, {1, 3, 0}},
+		{{6, 6, 0}, {8, 6, 0}, {8, 8, 0}, {6, 8, 0}},
+	}
+	inds := Triangulate(outline, holes...)
+
+	points := append([]Vec3{}, outline...)
+	for _, h := range holes {
+		points = append(points, h...)
+	}
+	for _, i := range inds {
+		if int(i) >= len(points) {
+			t.Fatalf("Index out of range: %d", i)
+		}
+	}
+	// Each hole adds two bridge vertices to the ring
+	if len(inds) != 3 * (len(points) + 4 - 2) {
+		t.Fatalf("Unexpected triangle count: %d", len(inds) / 3)
+	}
+	checkArea(t, triangleArea(points, inds), 100 - 4 - 4)
+}
+
+func TestFillEllipseBounds(t *testing.T) {
+	g := NewGeomDraw()
+	mesh := g.Circle(Vec3{5, 5, 0}, 2, 0)
+	bounds := mesh.Bounds()
+	if math.Abs(float64(bounds.Min[0] - 3)) > 0.01 || math.Abs(float64(bounds.Max[1] - 7)) > 0.01 {
+		t.Fatalf("Unexpected bounds: %v", bounds)
+	}
+	if len(mesh.indices) != 3 * g.Divisions {
+		t.Fatalf("Expected %d triangles, got %d", g.Divisions, len(mesh.indices) / 3)
+	}
+}
@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"github.com/unitoftime/glitch"
+)
+
+// A Drawer which draws a rounded box out of geometry, so panels can be themed without any textures
+type ShapePanel struct {
+	Top, Bottom glitch.RGBA // The fill is a vertical gradient between these
+	Border glitch.RGBA
+	BorderWidth float32 // 0 draws no border
+	Radii glitch.CornerRadii
+	Feather float32 // Anti aliases the edges (See glitch.GeomDraw.Feather)
+
+	geom *glitch.GeomDraw
+}
+
+// Creates a panel filled with a single color
+func NewShapePanel(color glitch.RGBA, radius float32) *ShapePanel {
+	return &ShapePanel{
+		Top: color,
+		Bottom: color,
+		Radii: glitch.Radii(radius),
+		Feather: 1,
+		geom: glitch.NewGeomDraw(),
+	}
+}
+
+// Returns the smallest rect that fits the corners
+func (s *ShapePanel) Bounds() glitch.Rect {
+	w := max32(s.Radii.TopLeft + s.Radii.TopRight, s.Radii.BottomLeft + s.Radii.BottomRight)
+	h := max32(s.Radii.TopLeft + s.Radii.BottomLeft, s.Radii.TopRight + s.Radii.BottomRight)
+	return glitch.R(0, 0, w, h)
+}
+
+func (s *ShapePanel) RectDraw(pass *glitch.RenderPass, bounds glitch.Rect) {
+	s.RectDrawColorMask(pass, bounds, glitch.RGBA{1, 1, 1, 1})
+}
+
+func (s *ShapePanel) RectDrawColorMask(pass *glitch.RenderPass, bounds glitch.Rect, mask glitch.RGBA) {
+	if s.geom == nil {
+		s.geom = glitch.NewGeomDraw()
+	}
+	s.geom.Feather = s.Feather
+
+	if s.Top == s.Bottom {
+		s.geom.SetColor(s.Top)
+	} else {
+		s.geom.SetGradient(glitch.NewLinearGradient(
+			glitch.Vec2{bounds.Min[0], bounds.Min[1]},
+			glitch.Vec2{bounds.Min[0], bounds.Max[1]},
+			glitch.GradientStop{Offset: 0, Color: s.Bottom},
+			glitch.GradientStop{Offset: 1, Color: s.Top},
+		))
+	}
+	mesh := s.geom.RoundedRect(bounds, s.Radii, 0)
+
+	// The border is drawn on top of the edge of the fill, so there is no seam between them
+	if s.BorderWidth > 0 {
+		s.geom.SetColor(s.Border)
+		mesh.Append(s.geom.RoundedRect(bounds, s.Radii, s.BorderWidth))
+	}
+
+	pass.Add(mesh, glitch.Mat4Ident, mask, glitch.DefaultMaterial())
+}
+
+func max32(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}